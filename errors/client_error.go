@@ -30,6 +30,18 @@ const (
 	JsonUnmarshalErrorCode    = "JsonUnmarshalError"
 	JsonUnmarshalErrorMessage = "Failed to unmarshal response,try using err.Message() to get detail message"
 
+	XmlUnmarshalErrorCode    = "XmlUnmarshalError"
+	XmlUnmarshalErrorMessage = "Failed to unmarshal xml response,try using err.Message() to get detail message"
+
+	UnsupportedCharsetErrorCode    = "UnsupportedCharset"
+	UnsupportedCharsetErrorMessage = "The charset (%s) is not supported, register a decoder with network.RegisterCharset"
+
+	BodyTooLargeErrorCode    = "BodyTooLarge"
+	BodyTooLargeErrorMessage = "The response body exceeds the limit of %d bytes"
+
+	CircuitOpenErrorCode    = "CircuitOpen"
+	CircuitOpenErrorMessage = "The circuit breaker for host (%s) is open, requests are rejected"
+
 	TimeoutErrorCode    = "TimeoutError"
 	TimeoutErrorMessage = "The request timed out %s times(%s for retry), perhaps we should have the threshold raised a little?"
 )
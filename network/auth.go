@@ -0,0 +1,236 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator 为请求添加认证信息, 每次发送(包括重试)前调用
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthInvalidator 可选接口, 收到 401 时调用
+// 返回 true 表示认证信息已失效, 客户端会重新调用 Authenticate 并重试一次
+type AuthInvalidator interface {
+	Invalidate(req *http.Request) bool
+}
+
+// AuthenticatorFunc 函数形式的 Authenticator
+type AuthenticatorFunc func(req *http.Request) error
+
+// Authenticate 实现 Authenticator
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// Authenticate 实现 Authenticator
+func (a BasicAuth) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// 静态的 Bearer Token 认证
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// API Key 认证, 例如 APIKey("X-API-Key", "secret")
+func APIKey(header, key string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set(header, key)
+		return nil
+	})
+}
+
+// 设置认证方式, 设置后 Auth(BasicAuth) 不再生效
+func (c *Client) SetAuthenticator(auth Authenticator) {
+	c.authenticator = auth
+}
+
+// 设置认证方式
+func WithAuthenticator(auth Authenticator) Option {
+	return func(c *Client) error {
+		c.authenticator = auth
+		return nil
+	}
+}
+
+// 为请求添加认证信息
+func (c *Client) authenticate(req *http.Request) error {
+	if c.authenticator == nil {
+		return nil
+	}
+	return c.authenticator.Authenticate(req)
+}
+
+// 收到 401 且认证信息可刷新时, 返回重新认证后的请求
+func (c *Client) reauthenticate(req *http.Request, resp *http.Response) *http.Request {
+	if resp.StatusCode != http.StatusUnauthorized {
+		return nil
+	}
+
+	invalidator, ok := c.authenticator.(AuthInvalidator)
+	if !ok || !invalidator.Invalidate(req) {
+		return nil
+	}
+
+	// 请求体无法重放
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return nil
+	}
+
+	retryReq := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil
+		}
+		retryReq.Body = body
+	}
+
+	if err := c.authenticator.Authenticate(retryReq); err != nil {
+		return nil
+	}
+	return retryReq
+}
+
+// ClientCredentials OAuth2 client credentials 模式获取 token
+// 缓存 token, 过期前自动刷新, 收到 401 时刷新并重试一次
+type ClientCredentials struct {
+	TokenURL       string
+	ClientID       string
+	ClientSecret   string
+	Scopes         []string
+	EndpointParams url.Values    // 额外的请求参数, 例如 audience
+	AuthInBody     bool          // 将 client_id/client_secret 放在请求体中, 默认使用 BasicAuth
+	ExpiryDelta    time.Duration // 提前刷新的时间, 默认 10s
+	HTTPClient     *http.Client  // 获取 token 使用的客户端, 默认 http.DefaultClient
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// token 接口的响应
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Authenticate 实现 Authenticator
+func (cc *ClientCredentials) Authenticate(req *http.Request) error {
+	token, err := cc.Token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Invalidate 实现 AuthInvalidator
+func (cc *ClientCredentials) Invalidate(req *http.Request) bool {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	// 其他请求已经刷新过 token
+	if req.Header.Get("Authorization") == "Bearer "+cc.token {
+		cc.token = ""
+		cc.expiry = time.Time{}
+	}
+	return true
+}
+
+// Token 返回缓存的 token, 即将过期时重新获取
+func (cc *ClientCredentials) Token(ctx context.Context) (string, error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	delta := cc.ExpiryDelta
+	if delta <= 0 {
+		delta = 10 * time.Second
+	}
+
+	if cc.token != "" && (cc.expiry.IsZero() || time.Now().Add(delta).Before(cc.expiry)) {
+		return cc.token, nil
+	}
+
+	token, err := cc.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	cc.token = token.AccessToken
+	cc.expiry = time.Time{}
+	if token.ExpiresIn > 0 {
+		cc.expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return cc.token, nil
+}
+
+// 请求 token 接口
+func (cc *ClientCredentials) fetch(ctx context.Context) (*tokenResponse, error) {
+	form := url.Values{}
+	for k, v := range cc.EndpointParams {
+		form[k] = v
+	}
+	form.Set("grant_type", "client_credentials")
+	if len(cc.Scopes) > 0 {
+		form.Set("scope", strings.Join(cc.Scopes, " "))
+	}
+	if cc.AuthInBody {
+		form.Set("client_id", cc.ClientID)
+		form.Set("client_secret", cc.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, POST, cc.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: %w", err)
+	}
+	req.Header.Set("Content-Type", XwwwFormUrlencoded)
+	if !cc.AuthInBody {
+		req.SetBasicAuth(url.QueryEscape(cc.ClientID), url.QueryEscape(cc.ClientSecret))
+	}
+
+	httpClient := cc.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("oauth2: cannot fetch token: %s %s", resp.Status, body)
+	}
+
+	token := &tokenResponse{}
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, fmt.Errorf("oauth2: cannot parse token: %w", err)
+	}
+
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2: server response missing access_token")
+	}
+
+	return token, nil
+}
@@ -0,0 +1,128 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 测试静态认证方式
+func TestAuthenticator_Static(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s|%s", r.Header.Get("Authorization"), r.Header.Get("X-API-Key"))
+	})
+	defer server.Close()
+
+	response, _ := NewClient(WithAuthenticator(BearerToken("t0k"))).Request("GET", server.URL, nil, 0)
+	if response.ToString() != "Bearer t0k|" {
+		t.Errorf("Unexpected bearer auth '%s'", response.ToString())
+	}
+
+	response, _ = NewClient(WithAuthenticator(APIKey("X-API-Key", "secret"))).Request("GET", server.URL, nil, 0)
+	if response.ToString() != "|secret" {
+		t.Errorf("Unexpected api key auth '%s'", response.ToString())
+	}
+
+	client := NewClient()
+	client.SetAuthenticator(BasicAuth{Username: "u", Password: "p"})
+	response, _ = client.PostForm2(server.URL, map[string]string{"a": "b"})
+	if !strings.HasPrefix(response.ToString(), "Basic ") {
+		t.Errorf("Unexpected basic auth '%s'", response.ToString())
+	}
+}
+
+// 测试 client credentials 获取并缓存 token
+func TestClientCredentials(t *testing.T) {
+	var issued int32
+	tokenServer := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		id, secret, _ := r.BasicAuth()
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("scope") != "read write" || id != "id" || secret != "secret" {
+			t.Errorf("Unexpected token request %v %s %s", r.Form, id, secret)
+		}
+		n := atomic.AddInt32(&issued, 1)
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":3600}`, n)
+	})
+	defer tokenServer.Close()
+
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Authorization")))
+	})
+	defer server.Close()
+
+	cc := &ClientCredentials{TokenURL: tokenServer.URL, ClientID: "id", ClientSecret: "secret", Scopes: []string{"read", "write"}}
+	client := NewClient(WithAuthenticator(cc))
+
+	for i := 0; i < 3; i++ {
+		response, err := client.Request("GET", server.URL, nil, 0)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if response.ToString() != "Bearer token-1" {
+			t.Errorf("Expected cached token, got '%s'", response.ToString())
+		}
+	}
+
+	if atomic.LoadInt32(&issued) != 1 {
+		t.Errorf("Expected 1 token request, got %d", issued)
+	}
+
+	// 即将过期时刷新
+	cc.mu.Lock()
+	cc.expiry = time.Now().Add(5 * time.Second)
+	cc.mu.Unlock()
+
+	response, _ := client.Request("GET", server.URL, nil, 0)
+	if response.ToString() != "Bearer token-2" {
+		t.Errorf("Expected refreshed token, got '%s'", response.ToString())
+	}
+}
+
+// 测试收到 401 后刷新 token 并重试一次
+func TestClientCredentials_RetryOn401(t *testing.T) {
+	var issued int32
+	tokenServer := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&issued, 1)
+		fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":3600}`, n)
+	})
+	defer tokenServer.Close()
+
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		buf := make([]byte, 64)
+		n, _ := r.Body.Read(buf)
+		// token-1 已被服务端吊销
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write(buf[:n])
+	})
+	defer server.Close()
+
+	client := NewClient(WithAuthenticator(&ClientCredentials{TokenURL: tokenServer.URL, ClientID: "id", ClientSecret: "secret", AuthInBody: true}))
+	response, err := client.Request("POST", server.URL, []byte("payload"), 0)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if response.ToString() != "payload" {
+		t.Errorf("Expected body to be replayed, got '%s'", response.ToString())
+	}
+
+	if atomic.LoadInt32(&calls) != 2 || atomic.LoadInt32(&issued) != 2 {
+		t.Errorf("Expected 2 calls and 2 tokens, got %d and %d", calls, issued)
+	}
+
+	// token 服务不可用时返回错误
+	tokenServer.Close()
+	_, err = NewClient(WithAuthenticator(&ClientCredentials{TokenURL: tokenServer.URL})).RequestWithContext(context.Background(), "GET", server.URL, nil, 0)
+	if err == nil || !strings.Contains(err.Error(), "oauth2") {
+		t.Errorf("Expected oauth2 error, got %v", err)
+	}
+}
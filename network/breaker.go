@@ -0,0 +1,280 @@
+package network
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/shzy2012/common/errors"
+)
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // 关闭: 正常放行
+	CircuitOpen                         // 打开: 直接拒绝请求
+	CircuitHalfOpen                     // 半开: 放行少量探测请求
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerConfig 熔断器配置, 按 host 分别统计
+type CircuitBreakerConfig struct {
+	Window           time.Duration                             // 滚动统计窗口, 默认 10s
+	Buckets          int                                       // 窗口划分的桶数, 默认 10
+	MinRequests      int                                       // 窗口内请求数达到该值后才计算失败率, 默认 20
+	FailureRate      float64                                   // 失败率达到该值时打开熔断器, 默认 0.5
+	Cooldown         time.Duration                             // 打开后经过该时间进入半开状态, 默认 30s
+	HalfOpenRequests int                                       // 半开状态允许的探测请求数, 全部成功后关闭, 默认 1
+	IsFailure        func(resp *http.Response, err error) bool // 判断请求是否失败, 默认网络错误或 5xx
+}
+
+// CircuitBreakerStat 单个 host 的熔断器状态
+type CircuitBreakerStat struct {
+	State    CircuitState
+	Requests int       // 窗口内的请求数
+	Failures int       // 窗口内的失败数
+	OpenedAt time.Time // 最近一次打开的时间
+}
+
+// 按 host 管理熔断器
+type circuitBreaker struct {
+	config CircuitBreakerConfig
+	mu     sync.Mutex
+	hosts  map[string]*hostCircuit
+}
+
+// 单个 host 的熔断器
+type hostCircuit struct {
+	state            CircuitState
+	openedAt         time.Time
+	buckets          []circuitBucket
+	halfOpenInFlight int
+	halfOpenSuccess  int
+}
+
+// 统计桶
+type circuitBucket struct {
+	epoch    int64 // 桶对应的时间序号
+	requests int
+	failures int
+}
+
+func newCircuitBreaker(config CircuitBreakerConfig) *circuitBreaker {
+	if config.Window <= 0 {
+		config.Window = 10 * time.Second
+	}
+	if config.Buckets <= 0 {
+		config.Buckets = 10
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 20
+	}
+	if config.FailureRate <= 0 || config.FailureRate > 1 {
+		config.FailureRate = 0.5
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = 30 * time.Second
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isCircuitFailure
+	}
+	return &circuitBreaker{config: config, hosts: map[string]*hostCircuit{}}
+}
+
+// 默认的失败判断: 网络错误或 5xx
+func isCircuitFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp != nil && resp.StatusCode >= 500
+}
+
+// 请求被允许时的熔断器状态, 用于忽略之前轮次的请求结果
+type circuitTicket struct {
+	probe    bool      // 是否为半开状态的探测请求
+	openedAt time.Time // 探测请求所在轮次的打开时间
+}
+
+// 判断是否允许请求, 不允许时返回 CircuitOpen 错误
+func (b *circuitBreaker) allow(host string) (circuitTicket, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	h := b.host(host)
+	now := time.Now()
+
+	if h.state == CircuitOpen {
+		if now.Before(h.openedAt.Add(b.config.Cooldown)) {
+			return circuitTicket{}, errors.NewClientError(errors.CircuitOpenErrorCode, fmt.Sprintf(errors.CircuitOpenErrorMessage, host), nil)
+		}
+		h.state = CircuitHalfOpen
+		h.halfOpenInFlight = 0
+		h.halfOpenSuccess = 0
+	}
+
+	if h.state != CircuitHalfOpen {
+		return circuitTicket{}, nil
+	}
+
+	if h.halfOpenInFlight >= b.config.HalfOpenRequests {
+		return circuitTicket{}, errors.NewClientError(errors.CircuitOpenErrorCode, fmt.Sprintf(errors.CircuitOpenErrorMessage, host), nil)
+	}
+	h.halfOpenInFlight++
+	return circuitTicket{probe: true, openedAt: h.openedAt}, nil
+}
+
+// 是否为熔断器拒绝请求的错误
+func isCircuitOpen(err error) bool {
+	e, ok := err.(errors.Error)
+	return ok && e.ErrorCode() == errors.CircuitOpenErrorCode
+}
+
+// 记录请求结果, 主动取消的请求(包括对冲中被取消的请求)不计入成功或失败, 只归还探测名额
+// 半开状态只统计本轮的探测请求, 忽略之前放行的请求
+func (b *circuitBreaker) record(host string, ticket circuitTicket, resp *http.Response, err error) {
+	canceled := err != nil && stdErrors.Is(err, context.Canceled)
+	failed := !canceled && b.config.IsFailure(resp, err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	h := b.host(host)
+	now := time.Now()
+	current := ticket.probe && h.state == CircuitHalfOpen && h.openedAt.Equal(ticket.openedAt)
+
+	if canceled {
+		if current && h.halfOpenInFlight > 0 {
+			h.halfOpenInFlight--
+		}
+		return
+	}
+
+	switch h.state {
+	case CircuitHalfOpen:
+		if !current {
+			return
+		}
+		if failed {
+			b.open(h, now)
+			return
+		}
+		h.halfOpenSuccess++
+		if h.halfOpenSuccess >= b.config.HalfOpenRequests {
+			h.state = CircuitClosed
+			h.buckets = make([]circuitBucket, b.config.Buckets)
+		}
+	case CircuitClosed:
+		bucket := b.bucket(h, now)
+		bucket.requests++
+		if failed {
+			bucket.failures++
+		}
+
+		requests, failures := b.count(h, now)
+		if requests >= b.config.MinRequests && float64(failures)/float64(requests) >= b.config.FailureRate {
+			b.open(h, now)
+		}
+	}
+}
+
+func (b *circuitBreaker) open(h *hostCircuit, now time.Time) {
+	h.state = CircuitOpen
+	h.openedAt = now
+	h.buckets = make([]circuitBucket, b.config.Buckets)
+}
+
+func (b *circuitBreaker) host(host string) *hostCircuit {
+	h, ok := b.hosts[host]
+	if !ok {
+		h = &hostCircuit{buckets: make([]circuitBucket, b.config.Buckets)}
+		b.hosts[host] = h
+	}
+	return h
+}
+
+// 单个桶的时间长度
+func (b *circuitBreaker) width() int64 {
+	width := int64(b.config.Window) / int64(b.config.Buckets)
+	if width <= 0 {
+		width = 1
+	}
+	return width
+}
+
+// 当前时间所在的桶, 过期的桶会被重置
+func (b *circuitBreaker) bucket(h *hostCircuit, now time.Time) *circuitBucket {
+	epoch := now.UnixNano() / b.width()
+	bucket := &h.buckets[epoch%int64(len(h.buckets))]
+	if bucket.epoch != epoch {
+		*bucket = circuitBucket{epoch: epoch}
+	}
+	return bucket
+}
+
+// 统计窗口内的请求数和失败数
+func (b *circuitBreaker) count(h *hostCircuit, now time.Time) (requests, failures int) {
+	epoch := now.UnixNano() / b.width()
+	for _, bucket := range h.buckets {
+		if epoch-bucket.epoch < int64(len(h.buckets)) {
+			requests += bucket.requests
+			failures += bucket.failures
+		}
+	}
+	return requests, failures
+}
+
+func (b *circuitBreaker) stats() map[string]CircuitBreakerStat {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	stats := make(map[string]CircuitBreakerStat, len(b.hosts))
+	for host, h := range b.hosts {
+		requests, failures := b.count(h, now)
+		stats[host] = CircuitBreakerStat{State: h.state, Requests: requests, Failures: failures, OpenedAt: h.openedAt}
+	}
+	return stats
+}
+
+// 开启按 host 的熔断器
+func WithCircuitBreaker(config CircuitBreakerConfig) Option {
+	return func(c *Client) error {
+		c.SetCircuitBreaker(&config)
+		return nil
+	}
+}
+
+// 设置熔断器, 为 nil 时关闭熔断
+func (c *Client) SetCircuitBreaker(config *CircuitBreakerConfig) {
+	if config == nil {
+		c.breaker = nil
+		return
+	}
+	c.breaker = newCircuitBreaker(*config)
+}
+
+// 获取各 host 的熔断器状态, 未开启熔断时返回 nil
+func (c *Client) CircuitBreakerStats() map[string]CircuitBreakerStat {
+	if c.breaker == nil {
+		return nil
+	}
+	return c.breaker.stats()
+}
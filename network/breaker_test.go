@@ -0,0 +1,181 @@
+package network
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	commonErrors "github.com/shzy2012/common/errors"
+)
+
+// 测试失败率达到阈值后打开熔断器, 冷却后半开探测并关闭
+func TestCircuitBreaker(t *testing.T) {
+	var healthy int32
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	defer server.Close()
+
+	client := NewClient(WithCircuitBreaker(CircuitBreakerConfig{MinRequests: 4, FailureRate: 0.5, Cooldown: 100 * time.Millisecond}))
+
+	for i := 0; i < 4; i++ {
+		client.Request("GET", server.URL, nil, 0)
+	}
+
+	_, err := client.Request("GET", server.URL, nil, 0)
+	if e, ok := err.(commonErrors.Error); !ok || e.ErrorCode() != commonErrors.CircuitOpenErrorCode {
+		t.Fatalf("Expected CircuitOpen error, got %v", err)
+	}
+
+	if atomic.LoadInt32(&calls) != 4 {
+		t.Errorf("Expected open circuit to reject without calling server, got %d calls", calls)
+	}
+
+	for host, stat := range client.CircuitBreakerStats() {
+		if stat.State != CircuitOpen {
+			t.Errorf("Expected %s to be open, got %s", host, stat.State)
+		}
+	}
+
+	// 冷却后放行探测请求, 成功则关闭
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(150 * time.Millisecond)
+
+	response, err := client.Request("GET", server.URL, nil, 0)
+	if err != nil || response.ToString() != "ok" {
+		t.Fatalf("Expected probe to succeed, got %v", err)
+	}
+
+	for host, stat := range client.CircuitBreakerStats() {
+		if stat.State != CircuitClosed {
+			t.Errorf("Expected %s to be closed, got %s", host, stat.State)
+		}
+	}
+
+	if _, ok := client.GetConnectionPoolStats()["CircuitBreakers"]; !ok {
+		t.Error("Expected circuit breaker stats in pool stats")
+	}
+}
+
+// 测试半开状态探测失败后重新打开
+func TestCircuitBreaker_HalfOpenFailure(t *testing.T) {
+	b := newCircuitBreaker(CircuitBreakerConfig{MinRequests: 1, Cooldown: 50 * time.Millisecond})
+	failed := &http.Response{StatusCode: http.StatusBadGateway}
+	ok := &http.Response{StatusCode: http.StatusOK}
+
+	// 关闭状态放行, 结束前进入半开状态
+	late, _ := b.allow("h")
+	ticket, _ := b.allow("h")
+	b.record("h", ticket, failed, nil)
+	if _, err := b.allow("h"); err == nil {
+		t.Fatal("Expected circuit to be open")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	probe, err := b.allow("h")
+	if err != nil {
+		t.Fatalf("Expected probe to be allowed, got %v", err)
+	}
+	if _, err := b.allow("h"); err == nil {
+		t.Error("Expected only one probe in half-open state")
+	}
+
+	// 半开之前放行的请求不计入探测结果
+	b.record("h", late, ok, nil)
+	if stat := b.stats()["h"]; stat.State != CircuitHalfOpen {
+		t.Errorf("Expected stale result to be ignored, got %s", stat.State)
+	}
+
+	// 取消的探测归还名额, 不计入成功
+	b.record("h", probe, nil, context.Canceled)
+	if stat := b.stats()["h"]; stat.State != CircuitHalfOpen {
+		t.Errorf("Expected canceled probe to be ignored, got %s", stat.State)
+	}
+	probe, err = b.allow("h")
+	if err != nil {
+		t.Fatalf("Expected released probe slot to be reusable, got %v", err)
+	}
+
+	b.record("h", probe, failed, nil)
+	if stat := b.stats()["h"]; stat.State != CircuitOpen {
+		t.Errorf("Expected circuit to reopen, got %s", stat.State)
+	}
+}
+
+// 测试限流等待超时时不占用探测名额
+func TestCircuitBreaker_ReleaseOnLimiterTimeout(t *testing.T) {
+	var healthy int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(
+		WithCircuitBreaker(CircuitBreakerConfig{MinRequests: 1, Cooldown: 50 * time.Millisecond}),
+		WithRateLimit(RateLimitConfig{Default: RateLimit{Rate: 0.1, Burst: 1}}),
+	)
+	client.Request("GET", server.URL, nil, 0)
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(60 * time.Millisecond)
+
+	// 令牌已用完, 探测请求在限流等待时超时
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.RequestWithContext(ctx, "GET", server.URL, nil, 0); err == nil {
+		t.Fatal("Expected limiter wait to fail")
+	}
+
+	client.SetRateLimit(nil)
+	if _, err := client.Request("GET", server.URL, nil, 0); err != nil {
+		t.Fatalf("Expected probe to be allowed, got %v", err)
+	}
+	for host, stat := range client.CircuitBreakerStats() {
+		if stat.State != CircuitClosed {
+			t.Errorf("Expected %s to be closed, got %s", host, stat.State)
+		}
+	}
+}
+
+// 测试取消的探测请求不会关闭熔断器
+func TestCircuitBreaker_CanceledProbe(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	defer server.Close()
+
+	client := NewClient(WithCircuitBreaker(CircuitBreakerConfig{MinRequests: 1, Cooldown: 50 * time.Millisecond}))
+	client.Request("GET", server.URL, nil, 0)
+	time.Sleep(60 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	client.RequestWithContext(ctx, "GET", server.URL+"/slow", nil, 0)
+
+	for host, stat := range client.CircuitBreakerStats() {
+		if stat.State != CircuitHalfOpen {
+			t.Errorf("Expected %s to stay half-open after canceled probe, got %s", host, stat.State)
+		}
+	}
+
+	// 名额已归还, 新的探测失败后重新打开
+	client.Request("GET", server.URL, nil, 0)
+	for host, stat := range client.CircuitBreakerStats() {
+		if stat.State != CircuitOpen {
+			t.Errorf("Expected %s to reopen, got %s", host, stat.State)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/shzy2012/common/errors"
+)
+
+// 路径参数, 例如 /users/{id}
+var pathParamPattern = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// RequestBuilder 链式构建请求
+/* example
+resp, err := client.NewRequest().
+	Method("GET").
+	Path("/users/{id}").
+	PathParam("id", "42").
+	Query("fields", "name").
+	Header("X-Tenant", "t1").
+	Do(ctx)
+*/
+type RequestBuilder struct {
+	client     *Client
+	method     string
+	path       string
+	pathParams map[string]string
+	query      url.Values
+	header     http.Header
+	body       []byte
+	retry      int
+	err        error
+}
+
+// 创建请求构建器, 默认为 GET 请求
+func (c *Client) NewRequest() *RequestBuilder {
+	return &RequestBuilder{
+		client:     c,
+		method:     GET,
+		pathParams: map[string]string{},
+		query:      url.Values{},
+		header:     http.Header{},
+	}
+}
+
+// Method 设置请求方法
+func (b *RequestBuilder) Method(method string) *RequestBuilder {
+	b.method = strings.ToUpper(method)
+	return b
+}
+
+// Path 设置请求路径, 相对路径拼接在客户端的基础地址之后, 支持 {name} 形式的路径参数
+func (b *RequestBuilder) Path(path string) *RequestBuilder {
+	b.path = path
+	return b
+}
+
+// PathParam 设置路径参数, 值会进行路径编码
+func (b *RequestBuilder) PathParam(key, value string) *RequestBuilder {
+	b.pathParams[key] = value
+	return b
+}
+
+// Query 添加查询参数, 同一个 key 可以添加多次
+func (b *RequestBuilder) Query(key, value string) *RequestBuilder {
+	b.query.Add(key, value)
+	return b
+}
+
+// Header 设置本次请求的header, 覆盖客户端的header
+func (b *RequestBuilder) Header(key, value string) *RequestBuilder {
+	b.header.Set(key, value)
+	return b
+}
+
+// Body 设置原始请求体
+func (b *RequestBuilder) Body(body []byte, contentType string) *RequestBuilder {
+	b.body = body
+	if contentType != "" {
+		b.header.Set(ContentType, contentType)
+	}
+	return b
+}
+
+// JSONBody 将 v 序列化为 JSON 请求体
+func (b *RequestBuilder) JSONBody(v interface{}) *RequestBuilder {
+	body, err := json.Marshal(v)
+	if err != nil {
+		b.err = errors.NewClientError(errors.JsonMarshalErrorCode, errors.JsonMarshalErrorMessage, err)
+		return b
+	}
+	return b.Body(body, "application/json;charset=utf-8")
+}
+
+// Retry 设置重试次数, 客户端设置了 RetryPolicy 时以策略为准
+func (b *RequestBuilder) Retry(retry int) *RequestBuilder {
+	b.retry = retry
+	return b
+}
+
+// URL 返回替换路径参数并拼接查询参数后的完整地址
+func (b *RequestBuilder) URL() (string, error) {
+	var missing []string
+	path := pathParamPattern.ReplaceAllStringFunc(b.path, func(m string) string {
+		key := m[1 : len(m)-1]
+		value, ok := b.pathParams[key]
+		if !ok {
+			missing = append(missing, key)
+			return m
+		}
+		return url.PathEscape(value)
+	})
+
+	if len(missing) > 0 {
+		msg := fmt.Sprintf("missing path params: %s", strings.Join(missing, ","))
+		return "", errors.NewClientError(errors.MissingParamErrorCode, msg, nil)
+	}
+
+	rawURL := b.client.resolveURL(path)
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		rawURL = "http://" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", errors.NewClientError(errors.InvalidParamErrorCode, fmt.Sprintf("invalid url: %s", rawURL), err)
+	}
+
+	if len(b.query) > 0 {
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
+		}
+		u.RawQuery += b.query.Encode()
+	}
+
+	return u.String(), nil
+}
+
+// Do 发起请求
+func (b *RequestBuilder) Do(ctx context.Context) (*HTTPResponse, error) {
+	if b.err != nil {
+		return &HTTPResponse{}, b.err
+	}
+
+	u, err := b.URL()
+	if err != nil {
+		return &HTTPResponse{}, err
+	}
+
+	return b.client.do(ctx, &request{
+		method: b.method,
+		url:    u,
+		rawURL: true,
+		header: b.header,
+		body:   b.body,
+		retry:  b.retry,
+	})
+}
@@ -0,0 +1,89 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	commonErrors "github.com/shzy2012/common/errors"
+)
+
+// 测试链式构建请求
+func TestRequestBuilder_Do(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != PUT {
+			t.Errorf("Expected PUT, got %s", r.Method)
+		}
+		if r.URL.EscapedPath() != "/api/users/a%2Fb" {
+			t.Errorf("Unexpected path '%s'", r.URL.EscapedPath())
+		}
+		if got := r.URL.Query()["tag"]; len(got) != 2 || got[0] != "x&y" || got[1] != "中文" {
+			t.Errorf("Unexpected query %v", r.URL.Query())
+		}
+		if r.Header.Get("X-Tenant") != "t2" || r.Header.Get("X-Client") != "c" {
+			t.Errorf("Unexpected header %v", r.Header)
+		}
+		if r.Header.Get("Content-Type") != "application/json;charset=utf-8" {
+			t.Errorf("Unexpected Content-Type '%s'", r.Header.Get("Content-Type"))
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var in map[string]string
+		json.Unmarshal(body, &in)
+		if in["name"] != "joey" {
+			t.Errorf("Unexpected body '%s'", body)
+		}
+		w.Write([]byte("ok"))
+	})
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL+"/api"), WithHeader("X-Tenant", "t1"), WithHeader("X-Client", "c"))
+	response, err := client.NewRequest().
+		Method("put").
+		Path("/users/{id}").
+		PathParam("id", "a/b").
+		Query("tag", "x&y").
+		Query("tag", "中文").
+		Header("X-Tenant", "t2").
+		JSONBody(map[string]string{"name": "joey"}).
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+
+	if response.ToString() != "ok" {
+		t.Errorf("Expected 'ok', got '%s'", response.ToString())
+	}
+
+	// 本次请求的header不影响客户端
+	if client.Header["X-Tenant"] != "t1" {
+		t.Error("Request header should not modify client header")
+	}
+}
+
+// 测试构建地址
+func TestRequestBuilder_URL(t *testing.T) {
+	client := NewClient(WithBaseURL("https://api.example.com/v1/"))
+
+	u, err := client.NewRequest().Path("search?q=a+b").Query("page", "2").URL()
+	if err != nil || u != "https://api.example.com/v1/search?q=a+b&page=2" {
+		t.Errorf("Unexpected url '%s' (%v)", u, err)
+	}
+
+	u, err = client.NewRequest().Path("http://other.com/x/{id}").PathParam("id", "1").URL()
+	if err != nil || u != "http://other.com/x/1" {
+		t.Errorf("Unexpected url '%s' (%v)", u, err)
+	}
+
+	_, err = client.NewRequest().Path("/users/{id}/{name}").PathParam("id", "1").URL()
+	if e, ok := err.(commonErrors.Error); !ok || e.ErrorCode() != commonErrors.MissingParamErrorCode {
+		t.Errorf("Expected MissingParam error, got %v", err)
+	}
+
+	_, err = client.NewRequest().JSONBody(make(chan int)).Do(context.Background())
+	if e, ok := err.(commonErrors.Error); !ok || e.ErrorCode() != commonErrors.JsonMarshalErrorCode {
+		t.Errorf("Expected JsonMarshalError, got %v", err)
+	}
+}
@@ -0,0 +1,412 @@
+package network
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheStorage 缓存存储, 实现需要并发安全; 存储失败时直接忽略即可
+type CacheStorage interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	Delete(key string)
+}
+
+// CacheStats 缓存统计
+type CacheStats struct {
+	Hits          int64 // 直接使用缓存的次数
+	Revalidations int64 // 服务端返回 304 后使用缓存的次数
+	Misses        int64 // 未使用缓存的次数
+}
+
+// 缓存的响应
+type cacheEntry struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+	StoredAt   time.Time
+	Vary       map[string]string // Vary 中列出的请求头的值
+}
+
+// 按 RFC 9111 缓存 GET 请求的响应
+type httpCache struct {
+	storage       CacheStorage
+	hits          int64
+	revalidations int64
+	misses        int64
+}
+
+// 解析 Cache-Control
+func parseCacheControl(header http.Header) map[string]string {
+	directives := map[string]string{}
+	for _, line := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(line, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			name, value, _ := strings.Cut(part, "=")
+			directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return directives
+}
+
+func cacheKey(req *http.Request) string {
+	return req.Method + " " + req.URL.String()
+}
+
+// 查找缓存, 返回缓存的响应以及是否可以直接使用
+func (hc *httpCache) lookup(req *http.Request) (*cacheEntry, bool) {
+	reqCC := parseCacheControl(req.Header)
+	if _, ok := reqCC["no-store"]; ok {
+		return nil, false
+	}
+
+	data, ok := hc.storage.Get(cacheKey(req))
+	if !ok {
+		return nil, false
+	}
+
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		hc.storage.Delete(cacheKey(req))
+		return nil, false
+	}
+
+	for name, value := range entry.Vary {
+		if req.Header.Get(name) != value {
+			return nil, false
+		}
+	}
+
+	if _, ok := reqCC["no-cache"]; ok {
+		return entry, false
+	}
+	if maxAge, ok := reqCC["max-age"]; ok {
+		if seconds, err := strconv.Atoi(maxAge); err == nil && entry.age() > time.Duration(seconds)*time.Second {
+			return entry, false
+		}
+	}
+
+	return entry, entry.age() < entry.lifetime()
+}
+
+// 缓存的年龄
+func (e *cacheEntry) age() time.Duration {
+	age := time.Since(e.StoredAt)
+	if seconds, err := strconv.Atoi(e.Header.Get("Age")); err == nil && seconds > 0 {
+		age += time.Duration(seconds) * time.Second
+	}
+	return age
+}
+
+// 新鲜度, max-age 优先于 Expires
+func (e *cacheEntry) lifetime() time.Duration {
+	cc := parseCacheControl(e.Header)
+	if _, ok := cc["no-cache"]; ok {
+		return 0
+	}
+	if maxAge, ok := cc["max-age"]; ok {
+		seconds, err := strconv.Atoi(maxAge)
+		if err != nil {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if expires := e.Header.Get("Expires"); expires != "" {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+		date, err := http.ParseTime(e.Header.Get("Date"))
+		if err != nil {
+			date = e.StoredAt
+		}
+		return t.Sub(date)
+	}
+	return 0
+}
+
+// 为过期的缓存添加条件请求头
+func (e *cacheEntry) validate(req *http.Request) bool {
+	etag := e.Header.Get("ETag")
+	lastModified := e.Header.Get("Last-Modified")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+	return etag != "" || lastModified != ""
+}
+
+// 保存响应, 不可缓存时删除旧的缓存
+func (hc *httpCache) store(req *http.Request, resp *http.Response, body []byte) {
+	key := cacheKey(req)
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+
+	reqCC := parseCacheControl(req.Header)
+	respCC := parseCacheControl(resp.Header)
+	_, reqNoStore := reqCC["no-store"]
+	_, respNoStore := respCC["no-store"]
+	if reqNoStore || respNoStore {
+		hc.storage.Delete(key)
+		return
+	}
+
+	// 缓存按地址共享(包括 Clone 的客户端), 带认证信息的请求只缓存明确允许共享的响应(RFC 9111 3.5)
+	if req.Header.Get("Authorization") != "" && !sharedAuthorized(respCC) {
+		return
+	}
+
+	entry := &cacheEntry{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+		StoredAt:   time.Now(),
+	}
+
+	// 既没有新鲜度也无法校验的响应不缓存
+	if entry.lifetime() <= 0 && entry.Header.Get("ETag") == "" && entry.Header.Get("Last-Modified") == "" {
+		hc.storage.Delete(key)
+		return
+	}
+
+	for _, line := range resp.Header.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				hc.storage.Delete(key)
+				return
+			}
+			if name != "" {
+				if entry.Vary == nil {
+					entry.Vary = map[string]string{}
+				}
+				entry.Vary[name] = req.Header.Get(name)
+			}
+		}
+	}
+
+	if data, err := json.Marshal(entry); err == nil {
+		hc.storage.Set(key, data)
+	}
+}
+
+// 带认证信息的请求的响应是否可以共享
+func sharedAuthorized(cc map[string]string) bool {
+	for _, name := range []string{"public", "s-maxage", "must-revalidate"} {
+		if _, ok := cc[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// 非安全方法请求成功后删除该地址的缓存
+func (hc *httpCache) invalidate(req *http.Request) {
+	hc.storage.Delete(GET + " " + req.URL.String())
+}
+
+// 收到 304 后使用新的响应头更新缓存
+func (hc *httpCache) refresh(req *http.Request, entry *cacheEntry, resp *http.Response) {
+	for name, values := range resp.Header {
+		entry.Header[name] = values
+	}
+	entry.StoredAt = time.Now()
+	if data, err := json.Marshal(entry); err == nil {
+		hc.storage.Set(cacheKey(req), data)
+	}
+}
+
+// 由缓存构造响应
+func (e *cacheEntry) response(req *http.Request) *HTTPResponse {
+	response := &HTTPResponse{ResponseBodyBytes: e.Body}
+	response.setOrigin(&http.Response{
+		StatusCode:    e.StatusCode,
+		Status:        e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header,
+		Body:          http.NoBody,
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	})
+	return response
+}
+
+// LRUCache 内存缓存, 超过字节上限时淘汰最久未使用的条目
+type LRUCache struct {
+	maxBytes int64
+	mu       sync.Mutex
+	size     int64
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	value []byte
+}
+
+// 创建内存缓存, maxBytes 为缓存的字节上限
+func NewLRUCache(maxBytes int64) *LRUCache {
+	return &LRUCache{maxBytes: maxBytes, ll: list.New(), items: map[string]*list.Element{}}
+}
+
+// Get 实现 CacheStorage
+func (l *LRUCache) Get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	l.ll.MoveToFront(el)
+	return el.Value.(*lruItem).value, true
+}
+
+// Set 实现 CacheStorage, 超过字节上限的条目不缓存
+func (l *LRUCache) Set(key string, value []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.remove(key)
+	size := int64(len(key) + len(value))
+	if size > l.maxBytes {
+		return
+	}
+
+	l.items[key] = l.ll.PushFront(&lruItem{key: key, value: value})
+	l.size += size
+	for l.size > l.maxBytes {
+		l.remove(l.ll.Back().Value.(*lruItem).key)
+	}
+}
+
+// Delete 实现 CacheStorage
+func (l *LRUCache) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.remove(key)
+}
+
+// Size 当前占用的字节数
+func (l *LRUCache) Size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size
+}
+
+// Len 当前的条目数
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ll.Len()
+}
+
+func (l *LRUCache) remove(key string) {
+	if el, ok := l.items[key]; ok {
+		item := el.Value.(*lruItem)
+		l.ll.Remove(el)
+		delete(l.items, key)
+		l.size -= int64(len(item.key) + len(item.value))
+	}
+}
+
+// DiskCache 磁盘缓存, 每个条目保存为目录下的一个文件
+type DiskCache struct {
+	dir string
+}
+
+// 创建磁盘缓存, 目录不存在时自动创建
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get 实现 CacheStorage
+func (d *DiskCache) Get(key string) ([]byte, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Set 实现 CacheStorage, 先写临时文件再重命名, 避免读到不完整的内容
+func (d *DiskCache) Set(key string, value []byte) {
+	f, err := os.CreateTemp(d.dir, "tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(value)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || os.Rename(f.Name(), d.path(key)) != nil {
+		os.Remove(f.Name())
+	}
+}
+
+// Delete 实现 CacheStorage
+func (d *DiskCache) Delete(key string) {
+	os.Remove(d.path(key))
+}
+
+// 开启 GET 请求的响应缓存, 带 Authorization 的请求只缓存 public、s-maxage 或 must-revalidate 的响应
+/* example
+client := network.NewClient(network.WithCache(network.NewLRUCache(64 << 20)))
+*/
+func WithCache(storage CacheStorage) Option {
+	return func(c *Client) error {
+		c.SetCache(storage)
+		return nil
+	}
+}
+
+// 设置响应缓存, 为 nil 时关闭缓存
+func (c *Client) SetCache(storage CacheStorage) {
+	if storage == nil {
+		c.cache = nil
+		return
+	}
+	c.cache = &httpCache{storage: storage}
+}
+
+// 获取缓存统计, 未开启缓存时返回零值
+func (c *Client) CacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+	return CacheStats{
+		Hits:          atomic.LoadInt64(&c.cache.hits),
+		Revalidations: atomic.LoadInt64(&c.cache.revalidations),
+		Misses:        atomic.LoadInt64(&c.cache.misses),
+	}
+}
@@ -0,0 +1,238 @@
+package network
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// 测试 max-age 内直接使用缓存
+func TestClient_Cache_MaxAge(t *testing.T) {
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		case "/nostore":
+			w.Header().Set("Cache-Control", "no-store")
+			w.Header().Set("ETag", `"x"`)
+		}
+		if r.Method == POST {
+			return
+		}
+		w.Write([]byte{'0' + byte(n)})
+	})
+	defer server.Close()
+
+	client := NewClient(WithCache(NewLRUCache(1 << 20)))
+	for i := 0; i < 3; i++ {
+		response, err := client.Request("GET", server.URL+"/fresh", nil, 0)
+		if err != nil || response.ToString() != "1" || response.StatusCode != 200 {
+			t.Fatalf("Expected cached body '1', got '%s' (%v)", response.ToString(), err)
+		}
+	}
+
+	if stats := client.CacheStats(); atomic.LoadInt32(&calls) != 1 || stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("Unexpected calls %d and stats %+v", calls, stats)
+	}
+
+	// 非安全方法使缓存失效
+	client.Request("POST", server.URL+"/fresh", nil, 0)
+	if response, _ := client.Request("GET", server.URL+"/fresh", nil, 0); response.ToString() != "3" {
+		t.Errorf("Expected POST to invalidate cache, got '%s'", response.ToString())
+	}
+
+	// no-store 的响应不缓存
+	client.Request("GET", server.URL+"/nostore", nil, 0)
+	if response, _ := client.Request("GET", server.URL+"/nostore", nil, 0); response.ToString() != "5" {
+		t.Errorf("Expected no-store response to be refetched, got '%s'", response.ToString())
+	}
+}
+
+// 测试 Vary: Accept-Encoding 的压缩响应能够命中缓存
+func TestClient_Cache_VaryAcceptEncoding(t *testing.T) {
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Encoding")
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(gzipBytes([]byte("hello")))
+			return
+		}
+		w.Write([]byte("hello"))
+	})
+	defer server.Close()
+
+	client := NewClient(WithCache(NewLRUCache(1 << 20)))
+	for i := 0; i < 3; i++ {
+		response, err := client.Request("GET", server.URL, nil, 0)
+		if err != nil || response.ToString() != "hello" {
+			t.Fatalf("Expected 'hello', got '%s' (%v)", response.ToString(), err)
+		}
+	}
+
+	if stats := client.CacheStats(); atomic.LoadInt32(&calls) != 1 || stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("Unexpected calls %d and stats %+v", calls, stats)
+	}
+}
+
+// 测试 Vary 使用请求拦截器设置的请求头
+func TestClient_Cache_VaryInterceptorHeader(t *testing.T) {
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "X-Tenant")
+		w.Write([]byte(r.Header.Get("X-Tenant")))
+	})
+	defer server.Close()
+
+	type tenantKey struct{}
+	client := NewClient(
+		WithCache(NewLRUCache(1<<20)),
+		WithRequestInterceptor(func(req *http.Request) error {
+			req.Header.Set("X-Tenant", req.Context().Value(tenantKey{}).(string))
+			return nil
+		}),
+	)
+
+	for _, tenant := range []string{"t1", "t1", "t2", "t2"} {
+		ctx := context.WithValue(context.Background(), tenantKey{}, tenant)
+		response, err := client.RequestWithContext(ctx, "GET", server.URL, nil, 0)
+		if err != nil || response.ToString() != tenant {
+			t.Fatalf("Expected '%s', got '%s' (%v)", tenant, response.ToString(), err)
+		}
+	}
+
+	if stats := client.CacheStats(); atomic.LoadInt32(&calls) != 2 || stats.Hits != 2 {
+		t.Errorf("Unexpected calls %d and stats %+v", calls, stats)
+	}
+}
+
+// 测试带认证信息的请求只缓存允许共享的响应
+func TestClient_Cache_Authorization(t *testing.T) {
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Path == "/public" {
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		} else {
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+		user, _, _ := r.BasicAuth()
+		w.Write([]byte(user))
+	})
+	defer server.Close()
+
+	alice := NewClient(WithCache(NewLRUCache(1<<20)), WithBasicAuth("alice", "p"))
+	bob := alice.Clone()
+	bob.Auth = BasicAuth{Username: "bob", Password: "p"}
+
+	alice.Request("GET", server.URL+"/private", nil, 0)
+	if response, _ := bob.Request("GET", server.URL+"/private", nil, 0); response.ToString() != "bob" {
+		t.Errorf("Expected private response not to be shared, got '%s'", response.ToString())
+	}
+
+	alice.Request("GET", server.URL+"/public", nil, 0)
+	if response, _ := bob.Request("GET", server.URL+"/public", nil, 0); response.ToString() != "alice" {
+		t.Errorf("Expected public response to be cached, got '%s'", response.ToString())
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("Expected 3 calls, got %d", n)
+	}
+}
+
+// 测试使用 ETag 和 Last-Modified 校验缓存
+func TestClient_Cache_Revalidate(t *testing.T) {
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.URL.Path == "/etag" {
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		} else {
+			w.Header().Set("Last-Modified", lastModified)
+			if r.Header.Get("If-Modified-Since") == lastModified {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.Write([]byte("body"))
+	})
+	defer server.Close()
+
+	client := NewClient(WithCache(NewLRUCache(1 << 20)))
+	for _, path := range []string{"/etag", "/modified"} {
+		for i := 0; i < 2; i++ {
+			response, err := client.Request("GET", server.URL+path, nil, 0)
+			if err != nil || response.StatusCode != 200 || response.ToString() != "body" {
+				t.Errorf("Expected body from %s, got %d '%s' (%v)", path, response.StatusCode, response.ToString(), err)
+			}
+		}
+	}
+
+	if stats := client.CacheStats(); atomic.LoadInt32(&calls) != 4 || stats.Revalidations != 2 || stats.Misses != 2 {
+		t.Errorf("Unexpected calls %d and stats %+v", calls, stats)
+	}
+}
+
+// 测试内存缓存按字节上限淘汰
+func TestLRUCache(t *testing.T) {
+	cache := NewLRUCache(25)
+	cache.Set("a", make([]byte, 9))
+	cache.Set("b", make([]byte, 9))
+	cache.Get("a")
+	cache.Set("c", make([]byte, 9))
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	if _, ok := cache.Get("a"); !ok || cache.Len() != 2 || cache.Size() != 20 {
+		t.Errorf("Unexpected cache state len=%d size=%d", cache.Len(), cache.Size())
+	}
+
+	cache.Set("big", make([]byte, 100))
+	if _, ok := cache.Get("big"); ok {
+		t.Error("Expected entry over budget not to be cached")
+	}
+
+	cache.Delete("a")
+	if cache.Len() != 1 {
+		t.Errorf("Expected 1 entry after delete, got %d", cache.Len())
+	}
+}
+
+// 测试磁盘缓存在客户端之间共享
+func TestDiskCache(t *testing.T) {
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte("disk"))
+	})
+	defer server.Close()
+
+	dir := t.TempDir()
+	storage, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	NewClient(WithCache(storage)).Request("GET", server.URL, nil, 0)
+
+	storage, _ = NewDiskCache(dir)
+	client := NewClient(WithCache(storage))
+	response, err := client.Request("GET", server.URL, nil, 0)
+	if err != nil || response.ToString() != "disk" || client.CacheStats().Hits != 1 || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected response from disk cache, got '%s' (%v) after %d calls", response.ToString(), err, calls)
+	}
+}
@@ -0,0 +1,167 @@
+package network
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// CharsetDecoder 将指定字符集的内容转换为 UTF-8
+type CharsetDecoder func(data []byte) ([]byte, error)
+
+// 全局注册的字符集解码器, 内置 UTF-8、US-ASCII、ISO-8859-1、UTF-16 以及 GBK、GB18030
+var charsets = struct {
+	sync.RWMutex
+	m map[string]CharsetDecoder
+}{m: map[string]CharsetDecoder{
+	"utf-8":      decodeUTF8,
+	"us-ascii":   decodeUTF8,
+	"iso-8859-1": decodeLatin1,
+	"utf-16":     decodeUTF16(nil),
+	"utf-16le":   decodeUTF16(binary.LittleEndian),
+	"utf-16be":   decodeUTF16(binary.BigEndian),
+	"gbk":        decodeGBK,
+	"gb18030":    decodeGB18030,
+}}
+
+// 字符集别名, GB2312 是 GBK 的子集
+var charsetAliases = map[string]string{
+	"utf8":      "utf-8",
+	"ascii":     "us-ascii",
+	"latin1":    "iso-8859-1",
+	"iso8859-1": "iso-8859-1",
+	"gb2312":    "gbk",
+	"cp936":     "gbk",
+	"x-gbk":     "gbk",
+}
+
+// 全局注册字符集解码器, 名称不区分大小写, decoder 为 nil 时删除
+/* example
+import "golang.org/x/text/encoding/traditionalchinese"
+
+network.RegisterCharset("big5", func(data []byte) ([]byte, error) {
+	return traditionalchinese.Big5.NewDecoder().Bytes(data)
+})
+*/
+func RegisterCharset(name string, decoder CharsetDecoder) {
+	charsets.Lock()
+	defer charsets.Unlock()
+	name = strings.ToLower(name)
+	if decoder == nil {
+		delete(charsets.m, name)
+		return
+	}
+	charsets.m[name] = decoder
+}
+
+// 查找字符集解码器
+func charsetDecoder(name string) CharsetDecoder {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := charsetAliases[name]; ok {
+		name = alias
+	}
+
+	charsets.RLock()
+	defer charsets.RUnlock()
+	return charsets.m[name]
+}
+
+// 转换为 UTF-8
+func toUTF8(data []byte, charset string) ([]byte, error) {
+	if charset == "" {
+		return decodeUTF8(data)
+	}
+	decoder := charsetDecoder(charset)
+	if decoder == nil {
+		return nil, fmt.Errorf("unsupported charset: %s", charset)
+	}
+	return decoder(data)
+}
+
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// 去掉 BOM
+func decodeUTF8(data []byte) ([]byte, error) {
+	return bytes.TrimPrefix(data, utf8BOM), nil
+}
+
+func decodeLatin1(data []byte) ([]byte, error) {
+	buf := make([]byte, 0, len(data))
+	for _, b := range data {
+		buf = utf8.AppendRune(buf, rune(b))
+	}
+	return buf, nil
+}
+
+func decodeGBK(data []byte) ([]byte, error) {
+	return simplifiedchinese.GBK.NewDecoder().Bytes(data)
+}
+
+func decodeGB18030(data []byte) ([]byte, error) {
+	return simplifiedchinese.GB18030.NewDecoder().Bytes(data)
+}
+
+// order 为 nil 时根据 BOM 判断字节序, 没有 BOM 时按大端处理
+func decodeUTF16(order binary.ByteOrder) CharsetDecoder {
+	return func(data []byte) ([]byte, error) {
+		byteOrder := order
+		switch {
+		case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+			data, byteOrder = data[2:], binary.BigEndian
+		case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+			data, byteOrder = data[2:], binary.LittleEndian
+		}
+		if byteOrder == nil {
+			byteOrder = binary.BigEndian
+		}
+		if len(data)%2 != 0 {
+			return nil, fmt.Errorf("invalid utf-16 data length %d", len(data))
+		}
+
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			units[i] = byteOrder.Uint16(data[i*2:])
+		}
+		return []byte(string(utf16.Decode(units))), nil
+	}
+}
+
+var (
+	metaCharset = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([\w-]+)`)
+	xmlEncoding = regexp.MustCompile(`(?i)<\?xml[^>]+encoding\s*=\s*["']([\w-]+)["']`)
+)
+
+// 从 BOM、HTML meta 或 XML 声明中识别字符集, 无法识别时合法的 UTF-8 返回 utf-8, 否则返回空字符串
+func sniffCharset(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, utf8BOM):
+		return "utf-8"
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		return "utf-16be"
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		return "utf-16le"
+	}
+
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	if m := xmlEncoding.FindSubmatch(head); m != nil {
+		return strings.ToLower(string(m[1]))
+	}
+	if m := metaCharset.FindSubmatch(head); m != nil {
+		return strings.ToLower(string(m[1]))
+	}
+
+	if utf8.Valid(data) {
+		return "utf-8"
+	}
+	return ""
+}
@@ -3,18 +3,16 @@ package network
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
 	"net/http/cookiejar"
-	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/shzy2012/common/errors"
-	"github.com/shzy2012/common/log"
 	"github.com/shzy2012/common/tools"
 )
 
@@ -50,11 +48,30 @@ func init() {
 // Client http 客户端
 type Client struct {
 	HttpClient          *http.Client
-	Header              map[string]string
+	Header              map[string]string // 并发修改请使用 SetHeader、DelHeader
 	Version             string
 	Debug               bool
 	Auth                BasicAuth
-	Cookies             []*http.Cookie
+	Cookies             []*http.Cookie // 并发修改请使用 SetCookie、ClearCookies
+	mu                  *sync.RWMutex  // 保护 Header 和 Cookies
+	retryPolicy         RetryPolicy
+	authenticator       Authenticator
+	breaker             *circuitBreaker
+	flight              *flightGroup
+	cache               *httpCache
+	limiter             *rateLimiter
+	hedge               *hedger
+	compressor          *compressor        // 请求体压缩
+	decoders            map[string]Decoder // 客户端注册的响应体解码器
+	upstreams           map[string]*upstreamGroup
+	pool                *poolMetrics // 连接池指标
+	metrics             MetricsHook
+	tracer              *tracer         // 请求 ID、traceparent 和调试日志
+	transport           *http.Transport // 连接池所在的 Transport
+	interceptors        interceptors
+	err                 error  // 配置项错误
+	baseURL             string // 相对路径请求的基础地址
+	logger              Logger
 	maxIdleConns        int
 	maxConnsPerHost     int
 	idleConnTimeout     time.Duration
@@ -67,7 +84,8 @@ type BasicAuth struct {
 }
 
 // 实例化http client
-func NewClient() *Client {
+// 默认校验服务端证书, 跳过校验需显式使用 WithInsecureSkipVerify
+func NewClient(opts ...Option) *Client {
 
 	header := map[string]string{"User-Agent": "go-client 1.0"}
 	jar, _ := cookiejar.New(nil)
@@ -78,9 +96,12 @@ func NewClient() *Client {
 	maxIdleConnsPerHost := 10
 	idleConnTimeout := 90 * time.Second
 
+	pool := newPoolMetrics(defaultDialContext())
+
 	// 创建自定义Transport
 	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		DialContext:     pool.dialContext,
+		TLSClientConfig: defaultTLSConfig(),
 		// 实际控制 HTTP 连接池的行为
 		MaxIdleConns:          maxIdleConns,
 		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
@@ -104,8 +125,13 @@ func NewClient() *Client {
 			Transport: transport,
 			Jar:       jar, //If Jar is nil, cookies are only sent if they are explicitly
 		},
-		Auth: BasicAuth{},
+		Auth:      BasicAuth{},
+		mu:        &sync.RWMutex{},
+		transport: transport,
+		pool:      pool,
+		logger:    defaultLogger{},
 	}
+	client.apply(opts...)
 	return client
 }
 
@@ -124,63 +150,115 @@ func (c *Client) Request(action, url string, input []byte, retry int) (*HTTPResp
 * action:POST\GET\PUT\PATCH\DELETE\HEAD\OPTIONS\TRACE\CONNECT
 * url:请求地址
 * input:请求参数
-* retry:重试次数,默认0(不重试)
+* retry:重试次数,默认0(不重试); 客户端设置了 RetryPolicy 时以策略为准
  */
-func (c *Client) RequestWithContext(ctx context.Context, action, url string, input []byte, retry int) (response *HTTPResponse, err error) {
-	response = &HTTPResponse{}
+func (c *Client) RequestWithContext(ctx context.Context, action, url string, input []byte, retry int) (*HTTPResponse, error) {
+	return c.do(ctx, &request{method: action, url: url, body: input, retry: retry})
+}
 
-	url, err = tools.URLCheck(url)
-	if err != nil {
-		errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
-		return response, errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
+// 单次请求的参数
+type request struct {
+	method string
+	url    string
+	rawURL bool        // url 已是完整地址, 不再经过 resolveURL 和 tools.URLCheck 处理
+	header http.Header // 本次请求的header, 覆盖客户端的header
+	body   []byte
+	retry  int
+
+	// 流式请求体, 每次发送前调用; replayable 为 false 时不重试
+	getBody       func() (io.ReadCloser, error)
+	contentLength int64
+	replayable    bool
+}
+
+// 发送请求, 开启请求合并时相同的幂等请求只发送一次
+func (c *Client) do(ctx context.Context, r *request) (*HTTPResponse, error) {
+	if c.flight != nil && IsIdempotent(strings.ToUpper(r.method)) {
+		return c.flight.do(ctx, c.flightKey(ctx, r), func(ctx context.Context) (*HTTPResponse, error) {
+			return c.execute(ctx, r)
+		})
 	}
+	return c.execute(ctx, r)
+}
 
-	if c.Debug {
-		log.Debugf("[http_request]=>%s to %s \n%s\n", action, url, input)
+// 发送请求(按重试策略重试)并读取响应体
+func (c *Client) execute(ctx context.Context, r *request) (response *HTTPResponse, err error) {
+	response = &HTTPResponse{}
+
+	var req *http.Request
+	var attempts int
+	start := time.Now()
+	defer func() {
+		response.Elapsed = time.Since(start)
+		response.Attempts = attempts
+		err = c.finish(req, strings.ToUpper(r.method), start, response, err)
+	}()
+
+	url := r.url
+	if !r.rawURL {
+		url, err = tools.URLCheck(c.resolveURL(url))
+		if err != nil {
+			errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
+			return response, errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
+		}
 	}
 
+	input := r.body
+
 	// 简化HTTP方法处理
-	action = strings.ToUpper(action)
+	action := strings.ToUpper(r.method)
 
 	// 确保retry非负
+	retry := r.retry
 	if retry < 0 {
 		retry = 0
 	}
 
 	// 构建HTTP请求模板
-	req, err := http.NewRequestWithContext(ctx, action, url, nil)
+	req, err = http.NewRequestWithContext(ctx, action, url, nil)
 	if err != nil {
 		errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
 		return response, errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
 	}
 
-	// 增加 BasicAuth
-	if strings.TrimSpace(c.Auth.Username) != "" {
-		req.SetBasicAuth(c.Auth.Username, c.Auth.Password)
-	}
-
 	// 设置默认Content-Type
 	if req.Header.Get("Content-Type") == "" {
 		req.Header.Set("Content-Type", "application/json;charset=utf-8")
 	}
 
-	// 设置header
-	for k, v := range c.Header {
-		req.Header.Set(k, v)
-		if c.Debug {
-			log.Debugf("[http_request_header]=>%s:%s \n", k, v)
-		}
+	// 设置 BasicAuth、header 和 cookies
+	c.prepareRequest(req)
+	for k, v := range r.header {
+		req.Header[k] = v
 	}
+	// 在查找缓存前设置, 保证 Vary: Accept-Encoding 的缓存能够命中
+	c.setAcceptEncoding(req)
 
-	// 设置cookies
-	for _, cookie := range c.Cookies {
-		req.AddCookie(cookie)
+	var logID string
+	if c.Debug {
+		logID = c.trace().logID(req)
 	}
 
-	var resp *http.Response
-	for i := 0; i <= retry; i++ {
-		// 每次重试都重新设置 Body
-		if input != nil {
+	// 压缩请求体
+	if c.compressor != nil && input != nil && r.getBody == nil {
+		if input, err = c.compressor.apply(req, input); err != nil {
+			return response, networkError(err)
+		}
+	}
+
+	// 每次发送都重新设置 Body
+	setBody := func() error {
+		if r.getBody != nil {
+			body, err := r.getBody()
+			if err != nil {
+				return err
+			}
+			req.Body = body
+			req.ContentLength = r.contentLength
+			if r.replayable {
+				req.GetBody = r.getBody
+			}
+		} else if input != nil {
 			bodyBytes := input
 			req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 			req.ContentLength = int64(len(bodyBytes))
@@ -188,12 +266,76 @@ func (c *Client) RequestWithContext(ctx context.Context, action, url string, inp
 				return io.NopCloser(bytes.NewReader(bodyBytes)), nil
 			}
 		}
+		return nil
+	}
 
-		resp, err = c.HttpClient.Do(req)
+	// 第一次发送的请求拦截器和认证在查找缓存前执行, 缓存的 Vary 与保存时使用相同的请求头
+	if err = setBody(); err == nil {
+		err = c.beforeSend(req)
+	}
+	if err != nil {
+		return response, networkError(err)
+	}
+
+	// 新鲜的缓存直接返回, 过期的缓存添加条件请求头
+	var cached *cacheEntry
+	if c.cache != nil && action == GET {
+		entry, fresh := c.cache.lookup(req)
+		if fresh {
+			atomic.AddInt64(&c.cache.hits, 1)
+			response = entry.response(req)
+			return response, nil
+		}
+		if entry != nil && entry.validate(req) {
+			cached = entry
+		}
+	}
+
+	policy := c.retryPolicy
+	if policy == nil {
+		policy = DefaultRetryPolicy(retry)
+	}
+	if r.getBody != nil && !r.replayable {
+		policy = DefaultRetryPolicy(0)
+	}
+
+	var resp *http.Response
+	for attempt := 1; ; attempt++ {
+		attempts = attempt
+		if attempt > 1 {
+			if err = setBody(); err != nil {
+				break
+			}
+			if err = c.beforeSend(req); err != nil {
+				break
+			}
+		}
+		if err = c.wait(req); err != nil {
+			break
+		}
+
+		if c.Debug {
+			c.trace().logRequest(c.logger, logID, attempt, req, input)
+		}
+		sent := time.Now()
+		resp, err = c.send(c.HttpClient, req, true)
+		if c.Debug {
+			c.trace().logResponse(c.logger, logID, attempt, resp, err, time.Since(sent))
+		}
+
+		// 熔断器拒绝时不重试
+		if isCircuitOpen(err) {
+			break
+		}
 
 		// 有错误或者状态码不是 2xx
 		isSuccess := err == nil && resp != nil && resp.StatusCode >= 200 && resp.StatusCode < 300
-		if isSuccess || i == retry {
+		if isSuccess {
+			break
+		}
+
+		backoffTime, ok := policy.Retry(attempt, time.Since(start), req, resp, err)
+		if !ok {
 			break
 		}
 
@@ -203,7 +345,6 @@ func (c *Client) RequestWithContext(ctx context.Context, action, url string, inp
 		}
 
 		// 添加退避时间
-		backoffTime := time.Duration(i+1) * time.Second
 		select {
 		case <-ctx.Done():
 			return response, ctx.Err()
@@ -212,8 +353,13 @@ func (c *Client) RequestWithContext(ctx context.Context, action, url string, inp
 	}
 
 	if err != nil {
-		errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
-		return response, errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
+		return response, networkError(err)
+	}
+
+	// 解码压缩的响应体
+	if err = c.decodeResponse(resp); err != nil {
+		resp.Body.Close()
+		return response, networkError(err)
 	}
 
 	// 确保响应体被正确关闭
@@ -226,11 +372,9 @@ func (c *Client) RequestWithContext(ctx context.Context, action, url string, inp
 		}()
 	}
 
-	response.StatusCode = resp.StatusCode
-	response.Status = resp.Status
-	response.OriginHTTPResponse = resp // 原始的Http Response
+	response.setOrigin(resp)
 
-	// 注意：大规模响应体可能有 OOM 风险
+	// 注意：大规模响应体可能有 OOM 风险, 大文件请使用 Stream 或 Download
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
@@ -238,11 +382,26 @@ func (c *Client) RequestWithContext(ctx context.Context, action, url string, inp
 	}
 
 	if c.Debug {
-		log.Debugf("[http_response]=>%s \n", bodyBytes)
+		c.trace().logResponseBody(c.logger, logID, resp.Header, bodyBytes, time.Since(start))
 	}
 
 	response.ResponseBodyBytes = bodyBytes // http 响应体
 
+	if c.cache != nil {
+		switch {
+		case action != GET && action != HEAD && resp.StatusCode < 400:
+			c.cache.invalidate(req)
+		case action == GET && cached != nil && resp.StatusCode == http.StatusNotModified:
+			atomic.AddInt64(&c.cache.revalidations, 1)
+			c.cache.refresh(req, cached, resp)
+			response = cached.response(req)
+			return response, nil
+		case action == GET:
+			atomic.AddInt64(&c.cache.misses, 1)
+			c.cache.store(req, resp, bodyBytes)
+		}
+	}
+
 	// 处理HTTP状态码
 	switch response.StatusCode {
 	case 200, 201, 202, 203, 204, 205, 206:
@@ -253,9 +412,130 @@ func (c *Client) RequestWithContext(ctx context.Context, action, url string, inp
 	}
 }
 
+// 配置了基础地址时, 将不带协议的相对路径拼接到基础地址之后
+func (c *Client) resolveURL(rawURL string) string {
+	if c.baseURL == "" || strings.HasPrefix(rawURL, "http://") || strings.HasPrefix(rawURL, "https://") {
+		return rawURL
+	}
+	return joinURL(c.baseURL, rawURL)
+}
+
+// 为请求设置 BasicAuth、客户端 header、cookies、请求 ID 和 traceparent
+func (c *Client) prepareRequest(req *http.Request) {
+	// 增加 BasicAuth, 设置了 Authenticator 时由其负责认证
+	if c.authenticator == nil && strings.TrimSpace(c.Auth.Username) != "" {
+		req.SetBasicAuth(c.Auth.Username, c.Auth.Password)
+	}
+
+	// 设置header
+	c.setHeader(req)
+
+	// 设置cookies
+	c.mu.RLock()
+	for _, cookie := range c.Cookies {
+		req.AddCookie(cookie)
+	}
+	c.mu.RUnlock()
+
+	// 设置请求 ID 和 traceparent
+	c.trace().inject(req)
+}
+
+// 发送前执行请求拦截器并添加认证信息, 发生重试时每次发送前都会调用
+// 开启缓存时第一次调用在查找缓存之前, 命中缓存时同样会执行
+func (c *Client) beforeSend(req *http.Request) error {
+	if err := c.interceptors.request(req); err != nil {
+		return err
+	}
+	return c.authenticate(req)
+}
+
+// 等待限流令牌
+func (c *Client) wait(req *http.Request) error {
+	if c.limiter == nil {
+		return nil
+	}
+	return c.limiter.wait(req.Context(), req.URL.Host)
+}
+
+// 发送请求, hedge 为 true 且开启对冲时幂等请求超时未返回会再发送一次
+func (c *Client) send(httpClient *http.Client, req *http.Request, hedge bool) (resp *http.Response, err error) {
+	if c.limiter != nil {
+		defer func() {
+			c.limiter.update(req.URL.Host, resp)
+		}()
+	}
+
+	if hedge && c.hedge != nil && c.hedge.eligible(req) {
+		return c.hedge.send(req, func(r *http.Request, hedged bool) (*http.Response, error) {
+			// 对冲请求分别获取限流令牌, 熔断由 sendOnce 处理
+			if hedged {
+				if err := c.wait(r); err != nil {
+					return nil, err
+				}
+			}
+			return c.route(httpClient, r)
+		})
+	}
+	return c.route(httpClient, req)
+}
+
+// 发送请求, host 为上游服务组时转发到组内节点
+func (c *Client) route(httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	if group := c.upstream(req.URL.Host); group != nil {
+		return group.send(c, httpClient, req)
+	}
+	return c.sendOnce(httpClient, req)
+}
+
+// 发送单个请求
+func (c *Client) sendOnce(httpClient *http.Client, req *http.Request) (resp *http.Response, err error) {
+	// 每个实际发送的请求(包括对冲请求和上游节点)分别经过熔断器
+	if c.breaker != nil {
+		ticket, allowErr := c.breaker.allow(req.URL.Host)
+		if allowErr != nil {
+			return nil, allowErr
+		}
+		defer func() {
+			c.breaker.record(req.URL.Host, ticket, resp, err)
+		}()
+	}
+
+	resp, err = c.roundTrip(httpClient, req)
+	if err != nil {
+		return resp, err
+	}
+
+	// 认证失效时刷新认证信息后重试一次
+	if retryReq := c.reauthenticate(req, resp); retryReq != nil {
+		resp.Body.Close()
+		return c.roundTrip(httpClient, retryReq)
+	}
+
+	return resp, nil
+}
+
+// 包装为网络错误, 已经是 errors.Error 的错误(例如熔断)直接返回
+func networkError(err error) error {
+	if e, ok := err.(errors.Error); ok {
+		return e
+	}
+	errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
+	return errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
+}
+
+// 设置重试策略, 为 nil 时按 Request 的 retry 参数使用默认策略
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retryPolicy = policy
+}
+
 // 设置Transport
+// 传入 *http.Transport 时作为新的连接池, 否则连接池设置仍作用于原有的 *http.Transport
 func (c *Client) SetTransport(transport http.RoundTripper) {
 	c.HttpClient.Transport = transport
+	if t, ok := transport.(*http.Transport); ok {
+		c.transport = t
+	}
 }
 
 // 设置http 超时时间
@@ -271,7 +551,7 @@ func (c *Client) SetConnectionPool(maxIdleConns, maxConnsPerHost, maxIdleConnsPe
 	c.idleConnTimeout = idleConnTimeout
 
 	// 更新Transport配置
-	if transport, ok := c.HttpClient.Transport.(*http.Transport); ok {
+	if transport := c.transport; transport != nil {
 		transport.MaxIdleConns = maxIdleConns
 		transport.MaxConnsPerHost = maxConnsPerHost
 		transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
@@ -282,7 +562,7 @@ func (c *Client) SetConnectionPool(maxIdleConns, maxConnsPerHost, maxIdleConnsPe
 // 设置最大空闲连接数
 func (c *Client) SetMaxIdleConns(maxIdleConns int) {
 	c.maxIdleConns = maxIdleConns
-	if transport, ok := c.HttpClient.Transport.(*http.Transport); ok {
+	if transport := c.transport; transport != nil {
 		transport.MaxIdleConns = maxIdleConns
 	}
 }
@@ -290,7 +570,7 @@ func (c *Client) SetMaxIdleConns(maxIdleConns int) {
 // 设置每个主机的最大连接数
 func (c *Client) SetMaxConnsPerHost(maxConnsPerHost int) {
 	c.maxConnsPerHost = maxConnsPerHost
-	if transport, ok := c.HttpClient.Transport.(*http.Transport); ok {
+	if transport := c.transport; transport != nil {
 		transport.MaxConnsPerHost = maxConnsPerHost
 	}
 }
@@ -298,7 +578,7 @@ func (c *Client) SetMaxConnsPerHost(maxConnsPerHost int) {
 // 设置空闲连接超时时间
 func (c *Client) SetIdleConnTimeout(timeout time.Duration) {
 	c.idleConnTimeout = timeout
-	if transport, ok := c.HttpClient.Transport.(*http.Transport); ok {
+	if transport := c.transport; transport != nil {
 		transport.IdleConnTimeout = timeout
 	}
 }
@@ -306,12 +586,14 @@ func (c *Client) SetIdleConnTimeout(timeout time.Duration) {
 // 设置每个主机的最大空闲连接数
 func (c *Client) SetMaxIdleConnsPerHost(maxIdleConnsPerHost int) {
 	c.maxIdleConnsPerHost = maxIdleConnsPerHost
-	if transport, ok := c.HttpClient.Transport.(*http.Transport); ok {
+	if transport := c.transport; transport != nil {
 		transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
 	}
 }
 
 // 获取连接池统计信息
+//
+// Deprecated: 使用 PoolStats 获取类型化的实时连接池指标
 func (c *Client) GetConnectionPoolStats() map[string]interface{} {
 	stats := map[string]interface{}{
 		"MaxIdleConns":        c.maxIdleConns,
@@ -320,13 +602,19 @@ func (c *Client) GetConnectionPoolStats() map[string]interface{} {
 		"IdleConnTimeout":     c.idleConnTimeout,
 	}
 
-	if transport, ok := c.HttpClient.Transport.(*http.Transport); ok {
+	if transport := c.transport; transport != nil {
 		stats["CurrentMaxIdleConns"] = transport.MaxIdleConns
 		stats["CurrentMaxConnsPerHost"] = transport.MaxConnsPerHost
 		stats["CurrentMaxIdleConnsPerHost"] = transport.MaxIdleConnsPerHost
 		stats["CurrentIdleConnTimeout"] = transport.IdleConnTimeout
 	}
 
+	if breakers := c.CircuitBreakerStats(); breakers != nil {
+		stats["CircuitBreakers"] = breakers
+	}
+
+	stats["Pool"] = c.PoolStats()
+
 	return stats
 }
 
@@ -337,33 +625,96 @@ func (c *Client) SetDebug(d bool) {
 
 // 添加cookie
 func (c *Client) SetCookie(cookie *http.Cookie) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.Cookies = append(c.Cookies, cookie)
 }
 
 // 清除cookies
 func (c *Client) ClearCookies() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.Cookies = c.Cookies[0:0]
 }
 
 // 释放客户端资源
 func (c *Client) Close() error {
 	// 关闭 HTTP 客户端的传输层
-	if c.HttpClient != nil && c.HttpClient.Transport != nil {
-		if transport, ok := c.HttpClient.Transport.(*http.Transport); ok {
-			// 关闭所有空闲连接
-			transport.CloseIdleConnections()
-		}
+	if c.transport != nil {
+		// 关闭所有空闲连接
+		c.transport.CloseIdleConnections()
 	}
 
 	// 清空 cookies
 	c.ClearCookies()
 
 	// 清空 headers
+	c.mu.Lock()
 	c.Header = make(map[string]string)
+	c.mu.Unlock()
 
 	return nil
 }
 
+// 复制客户端, 副本拥有独立的 header、cookies、cookie jar 和连接池
+// 通过 SetTransport 设置了自定义 RoundTripper 时, 副本与原客户端共用该 RoundTripper
+func (c *Client) Clone() *Client {
+	// 持有读锁复制, 避免与 SetHeader、SetCookie、Close 并发修改
+	c.mu.RLock()
+	clone := *c
+	clone.mu = &sync.RWMutex{}
+	clone.Header = make(map[string]string, len(c.Header))
+	for k, v := range c.Header {
+		clone.Header[k] = v
+	}
+	clone.Cookies = append([]*http.Cookie(nil), c.Cookies...)
+	if c.upstreams != nil {
+		clone.upstreams = make(map[string]*upstreamGroup, len(c.upstreams))
+		for name, group := range c.upstreams {
+			clone.upstreams[name] = group
+		}
+	}
+	if c.decoders != nil {
+		clone.decoders = make(map[string]Decoder, len(c.decoders))
+		for name, decoder := range c.decoders {
+			clone.decoders[name] = decoder
+		}
+	}
+	c.mu.RUnlock()
+
+	clone.interceptors = interceptors{
+		requests:  append([]RequestInterceptor(nil), c.interceptors.requests...),
+		responses: append([]ResponseInterceptor(nil), c.interceptors.responses...),
+	}
+
+	httpClient := *c.HttpClient
+	clone.HttpClient = &httpClient
+	if c.HttpClient.Jar != nil {
+		clone.HttpClient.Jar, _ = cookiejar.New(nil)
+	}
+
+	if c.pool != nil {
+		clone.pool = newPoolMetrics(c.pool.dial)
+	}
+
+	// 合并的 key 不包含客户端的header, 不能与原客户端共用
+	if c.flight != nil {
+		clone.flight = &flightGroup{calls: map[string]*flightCall{}}
+	}
+
+	if c.transport != nil {
+		clone.transport = c.transport.Clone()
+		if clone.pool != nil {
+			clone.transport.DialContext = clone.pool.dialContext
+		}
+		if c.HttpClient.Transport == c.transport {
+			clone.HttpClient.Transport = clone.transport
+		}
+	}
+
+	return &clone
+}
+
 // 发起HTTP Get请求
 func Get(url string) ([]byte, error) {
 	return GetWithContext(context.Background(), url)
@@ -422,106 +773,24 @@ func (c *Client) PostForm(url string, form map[string]io.Reader) (response *HTTP
 }
 
 // 发起带 Context 的 PostForm 请求
+// *os.File 和 *FormFile 作为文件上传, 其他 io.Reader 作为普通字段; 文件以流的方式发送
+// 请求结束后关闭 form 中实现了 io.Closer 的 Reader
 func (c *Client) PostFormWithContext(ctx context.Context, url string, form map[string]io.Reader) (response *HTTPResponse, err error) {
-	response = &HTTPResponse{}
-
-	// Prepare a form that you will submit to that URL.
-	var b bytes.Buffer
-	w := multipart.NewWriter(&b)
-
-	// 确保 multipart writer 被正确关闭
 	defer func() {
-		if closeErr := w.Close(); err == nil && closeErr != nil {
-			err = closeErr
-		}
-	}()
-
-	for key, r := range form {
-		var fw io.Writer
-		if x, ok := r.(io.Closer); ok {
-			defer func(closer io.Closer) {
+		for _, r := range form {
+			if closer, ok := r.(io.Closer); ok {
 				if closeErr := closer.Close(); err == nil && closeErr != nil {
 					err = closeErr
 				}
-			}(x)
-		}
-		// file
-		if x, ok := r.(*os.File); ok {
-			if fw, err = w.CreateFormFile(key, x.Name()); err != nil {
-				return response, errors.NewClientError(errors.NetWorkErrorCode, "Failed to create form file", err)
 			}
-		} else {
-			// Add other fields
-			if fw, err = w.CreateFormField(key); err != nil {
-				return response, errors.NewClientError(errors.NetWorkErrorCode, "Failed to create form field", err)
-			}
-		}
-		if _, err = io.Copy(fw, r); err != nil {
-			return response, errors.NewClientError(errors.NetWorkErrorCode, "Failed to copy form data", err)
-		}
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", url, &b)
-	if err != nil {
-		errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
-		return response, errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
-	}
-
-	//设置header
-	for k, v := range c.Header {
-		req.Header.Set(k, v)
-		if c.Debug {
-			log.Debugf("[header]=>%s:%s \n", k, v)
 		}
-	}
-
-	// Don't forget to set the content type, this will contain the boundary.
-	req.Header.Set("Content-Type", w.FormDataContentType())
-
-	resp, err := c.HttpClient.Do(req)
-	if err != nil {
-		errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
-		return response, errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
-	}
-
-	// 确保响应体被正确关闭
-	if resp != nil && resp.Body != nil {
-		defer func() {
-			closeErr := resp.Body.Close()
-			if err == nil && closeErr != nil {
-				err = closeErr
-			}
-		}()
-	}
-
-	response.StatusCode = resp.StatusCode
-	response.Status = resp.Status
-	response.OriginHTTPResponse = resp //原始的Http Response
+	}()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	fields, files, err := formParts(form)
 	if err != nil {
-		errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
-		return response, errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
-	}
-
-	if c.Debug {
-		log.Debugf("[http resp]=>%s \n", bodyBytes)
-	}
-
-	response.ResponseBodyBytes = bodyBytes //http 响应体
-	/*
-		https://developer.mozilla.org/zh-CN/docs/Web/HTTP/Status/201
-		200 OK
-		201 Created
-		202 Accepted
-	*/
-	switch response.StatusCode {
-	case 200, 201, 202, 203, 204, 205, 206:
-		return response, nil
-	default:
-		response.Message = string(response.ResponseBodyBytes)
-		return response, errors.NewServerError(resp.StatusCode, response.Message, err)
+		return &HTTPResponse{}, errors.NewClientError(errors.NetWorkErrorCode, "Failed to copy form data", err)
 	}
+	return c.PostMultipart(ctx, url, fields, files, nil)
 }
 
 // x-www-form-urlencoded 方式
@@ -529,93 +798,7 @@ func (c *Client) PostForm2(url string, values map[string]string) (response *HTTP
 	return c.PostForm2WithContext(context.Background(), url, values)
 }
 
-// 发起带 Context 的 PostForm2 请求
+// 发起带 Context 的 PostForm2 请求, 重复的 key 请使用 PostFormValues
 func (c *Client) PostForm2WithContext(ctx context.Context, url string, values map[string]string) (response *HTTPResponse, err error) {
-	response = &HTTPResponse{}
-
-	// 构建 x-www-form-urlencoded 数据
-	data := make([]string, 0, len(values))
-	for key, value := range values {
-		// URL编码参数
-		encodedKey := strings.ReplaceAll(key, " ", "+")
-		encodedValue := strings.ReplaceAll(value, " ", "+")
-		data = append(data, fmt.Sprintf("%s=%s", encodedKey, encodedValue))
-	}
-
-	// 将参数用 & 连接
-	encodedData := strings.Join(data, "&")
-
-	if c.Debug {
-		log.Debugf("[form-data]=>%s \n", encodedData)
-	}
-
-	// 创建请求
-	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(encodedData))
-	if err != nil {
-		errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
-		return response, errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
-	}
-
-	// 设置Content-Type为x-www-form-urlencoded
-	req.Header.Set("Content-Type", XwwwFormUrlencoded)
-
-	// 设置header
-	for k, v := range c.Header {
-		req.Header.Set(k, v)
-		if c.Debug {
-			log.Debugf("[header]=>%s:%s \n", k, v)
-		}
-	}
-
-	// 增加 BasicAuth
-	if strings.TrimSpace(c.Auth.Username) != "" {
-		req.SetBasicAuth(c.Auth.Username, c.Auth.Password)
-	}
-
-	// 设置cookies
-	for _, cookie := range c.Cookies {
-		req.AddCookie(cookie)
-	}
-
-	// 发送请求
-	resp, err := c.HttpClient.Do(req)
-	if err != nil {
-		errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
-		return response, errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
-	}
-
-	// 确保响应体被正确关闭
-	if resp != nil && resp.Body != nil {
-		defer func() {
-			closeErr := resp.Body.Close()
-			if err == nil && closeErr != nil {
-				err = closeErr
-			}
-		}()
-	}
-
-	response.StatusCode = resp.StatusCode
-	response.Status = resp.Status
-	response.OriginHTTPResponse = resp // 原始的Http Response
-
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
-		return response, errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
-	}
-
-	if c.Debug {
-		log.Debugf("[http resp]=>%s \n", bodyBytes)
-	}
-
-	response.ResponseBodyBytes = bodyBytes // http 响应体
-
-	// 处理HTTP状态码
-	switch response.StatusCode {
-	case 200, 201, 202, 203, 204, 205, 206:
-		return response, nil
-	default:
-		response.Message = string(response.ResponseBodyBytes)
-		return response, errors.NewServerError(resp.StatusCode, response.Message, err)
-	}
+	return c.PostFormValues(ctx, url, values)
 }
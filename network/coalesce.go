@@ -0,0 +1,122 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// 合并相同的进行中请求, 只发起一次网络调用
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// 进行中的请求
+type flightCall struct {
+	done    chan struct{}
+	resp    *HTTPResponse
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// 执行或加入相同 key 的请求, 所有等待者都离开后取消请求
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (*HTTPResponse, error)) (*HTTPResponse, error) {
+	g.mu.Lock()
+	call, ok := g.calls[key]
+	if !ok {
+		// 请求不随单个调用方取消, 保留 ctx 中的值
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+
+		go func() {
+			defer cancel()
+			call.resp, call.err = fn(callCtx)
+
+			g.mu.Lock()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+			g.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return copyResponse(call.resp), call.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		return &HTTPResponse{}, networkError(ctx.Err())
+	}
+}
+
+// 复制响应, 避免等待者之间相互影响
+func copyResponse(resp *HTTPResponse) *HTTPResponse {
+	if resp == nil {
+		return &HTTPResponse{}
+	}
+	cp := *resp
+	cp.ResponseBodyBytes = bytes.Clone(resp.ResponseBodyBytes)
+	cp.Header = resp.Header.Clone()
+	return &cp
+}
+
+// 请求的唯一标识: 方法、地址、本次请求的header和请求体
+func (c *Client) flightKey(ctx context.Context, r *request) string {
+	url := r.url
+	if !r.rawURL {
+		url = c.resolveURL(url)
+	}
+
+	header := http.Header{}
+	for k, v := range HeaderFromContext(ctx) {
+		header[k] = v
+	}
+	for k, v := range r.header {
+		header[k] = v
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", strings.ToUpper(r.method), url)
+	header.Write(h)
+	h.Write([]byte{'\n'})
+	h.Write(r.body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// 开启请求合并: 相同的进行中幂等请求只发起一次, 所有调用方获得响应的副本
+func WithCoalescing() Option {
+	return func(c *Client) error {
+		c.SetCoalescing(true)
+		return nil
+	}
+}
+
+// 设置是否合并相同的进行中幂等请求
+func (c *Client) SetCoalescing(enabled bool) {
+	if !enabled {
+		c.flight = nil
+		return
+	}
+	if c.flight == nil {
+		c.flight = &flightGroup{calls: map[string]*flightCall{}}
+	}
+}
@@ -0,0 +1,114 @@
+package network
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 测试相同的并发 GET 请求只发起一次
+func TestClient_Coalescing(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Write([]byte(r.URL.Query().Get("id")))
+	})
+	defer server.Close()
+
+	client := NewClient(WithCoalescing())
+
+	var wg sync.WaitGroup
+	responses := make([]*HTTPResponse, 10)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], _ = client.Request("get", server.URL+"?id=1", nil, 0)
+		}(i)
+	}
+
+	// 不同的地址不合并
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		client.Request("GET", server.URL+"?id=2", nil, 0)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("Expected 2 upstream calls, got %d", n)
+	}
+
+	for _, response := range responses {
+		if response.ToString() != "1" {
+			t.Fatalf("Expected shared body '1', got '%s'", response.ToString())
+		}
+	}
+
+	// 每个调用方获得独立的副本
+	responses[0].ResponseBodyBytes[0] = 'x'
+	if responses[1].ToString() != "1" {
+		t.Error("Expected waiters to receive a copy of the response")
+	}
+}
+
+// 测试调用方取消不影响其他等待者
+func TestClient_Coalescing_Cancel(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Write([]byte("ok"))
+	})
+	defer server.Close()
+
+	client := NewClient(WithCoalescing())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := client.RequestWithContext(ctx, "GET", server.URL, nil, 0)
+		first <- err
+	}()
+
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	second := make(chan *HTTPResponse, 1)
+	go func() {
+		response, _ := client.Request("GET", server.URL, nil, 0)
+		second <- response
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-first; err == nil {
+		t.Error("Expected cancelled caller to get an error")
+	}
+
+	close(release)
+	if response := <-second; response.ToString() != "ok" {
+		t.Errorf("Expected remaining waiter to get the response, got '%s'", response.ToString())
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Expected 1 upstream call, got %d", n)
+	}
+
+	// POST 不合并
+	client.Request("POST", server.URL, nil, 0)
+	client.Request("POST", server.URL, nil, 0)
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("Expected POST not to be coalesced, got %d calls", n)
+	}
+}
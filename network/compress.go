@@ -0,0 +1,327 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shzy2012/common/errors"
+)
+
+// Decoder 响应体解码器, 按 Content-Encoding 选择, 例如 br、zstd
+type Decoder func(r io.Reader) (io.ReadCloser, error)
+
+// 全局注册的解码器, 内置 gzip 和 deflate
+var decoders = struct {
+	sync.RWMutex
+	m map[string]Decoder
+}{m: map[string]Decoder{
+	"gzip":    gzipDecoder,
+	"x-gzip":  gzipDecoder,
+	"deflate": deflateDecoder,
+}}
+
+// 全局注册 Content-Encoding 的解码器, 对所有客户端生效, decoder 为 nil 时删除
+// 注册后请求会在 Accept-Encoding 中声明支持该编码以及内置的 gzip、deflate
+/* example
+network.RegisterDecoder("br", func(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(brotli.NewReader(r)), nil
+})
+*/
+func RegisterDecoder(encoding string, decoder Decoder) {
+	decoders.Lock()
+	defer decoders.Unlock()
+	encoding = strings.ToLower(encoding)
+	if decoder == nil {
+		delete(decoders.m, encoding)
+		return
+	}
+	decoders.m[encoding] = decoder
+}
+
+// 为客户端注册 Content-Encoding 的解码器, 优先于全局注册的解码器
+func WithDecoder(encoding string, decoder Decoder) Option {
+	return func(c *Client) error {
+		c.SetDecoder(encoding, decoder)
+		return nil
+	}
+}
+
+// 为客户端注册 Content-Encoding 的解码器, decoder 为 nil 时删除(并发安全)
+func (c *Client) SetDecoder(encoding string, decoder Decoder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	encoding = strings.ToLower(encoding)
+	if decoder == nil {
+		delete(c.decoders, encoding)
+		return
+	}
+	if c.decoders == nil {
+		c.decoders = map[string]Decoder{}
+	}
+	c.decoders[encoding] = decoder
+}
+
+func (c *Client) decoder(encoding string) Decoder {
+	c.mu.RLock()
+	decoder := c.decoders[encoding]
+	c.mu.RUnlock()
+	if decoder != nil {
+		return decoder
+	}
+
+	decoders.RLock()
+	defer decoders.RUnlock()
+	return decoders.m[encoding]
+}
+
+// 支持的编码, 用于 Accept-Encoding
+func (c *Client) acceptEncoding() string {
+	names := map[string]bool{}
+	decoders.RLock()
+	for name := range decoders.m {
+		names[name] = true
+	}
+	decoders.RUnlock()
+	c.mu.RLock()
+	for name := range c.decoders {
+		names[name] = true
+	}
+	c.mu.RUnlock()
+	delete(names, "x-gzip")
+
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	// gzip 和 deflate 在前, 其余按名称排序
+	sort.Slice(list, func(i, j int) bool {
+		ri, rj := encodingRank(list[i]), encodingRank(list[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return list[i] < list[j]
+	})
+	return strings.Join(list, ", ")
+}
+
+func encodingRank(name string) int {
+	switch name {
+	case "gzip":
+		return 0
+	case "deflate":
+		return 1
+	}
+	return 2
+}
+
+// 是否注册了内置(gzip、deflate)以外的解码器
+func (c *Client) customDecoders() bool {
+	decoders.RLock()
+	defer decoders.RUnlock()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, m := range []map[string]Decoder{decoders.m, c.decoders} {
+		for name := range m {
+			if name != "gzip" && name != "x-gzip" && name != "deflate" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 注册了内置以外的解码器时声明支持的编码, 请求已设置 Accept-Encoding 或 Range 时不修改
+// 设置后 http.Transport 不再自动解压 gzip, 由 decodeResponse 统一解码;
+// 未设置时由 http.Transport 声明 gzip 并透明解压, Transport 层的 RoundTripper(例如 nettest.Recorder)看到的是解压后的响应体
+func (c *Client) setAcceptEncoding(req *http.Request) {
+	if req.Header.Get("Accept-Encoding") != "" || req.Header.Get("Range") != "" || req.Method == http.MethodHead {
+		return
+	}
+	if c.transport != nil && c.transport.DisableCompression {
+		return
+	}
+	if !c.customDecoders() {
+		return
+	}
+	req.Header.Set("Accept-Encoding", c.acceptEncoding())
+}
+
+// 按 Content-Encoding 解码响应体, 多个编码时按相反的顺序解码
+// 存在未注册的编码时不解码
+func (c *Client) decodeResponse(resp *http.Response) error {
+	encodings := strings.Split(resp.Header.Get("Content-Encoding"), ",")
+	chain := make([]Decoder, 0, len(encodings))
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if encoding == "" || encoding == "identity" {
+			continue
+		}
+		decoder := c.decoder(encoding)
+		if decoder == nil {
+			return nil
+		}
+		chain = append(chain, decoder)
+	}
+	if len(chain) == 0 || resp.Body == nil || resp.Body == http.NoBody {
+		return nil
+	}
+
+	body := &decodedBody{raw: resp.Body, ReadCloser: resp.Body}
+	for _, decoder := range chain {
+		r, err := decoder(body.ReadCloser)
+		if err != nil {
+			// 空的响应体(例如 204)无需解码
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("decode %s response: %w", resp.Header.Get("Content-Encoding"), err)
+		}
+		body.decoders = append(body.decoders, r)
+		body.ReadCloser = r
+	}
+
+	resp.Body = body
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// 解码后的响应体, 关闭时同时关闭原始响应体
+type decodedBody struct {
+	io.ReadCloser
+	raw      io.ReadCloser
+	decoders []io.ReadCloser
+}
+
+func (b *decodedBody) Close() error {
+	for i := len(b.decoders) - 1; i >= 0; i-- {
+		b.decoders[i].Close()
+	}
+	return b.raw.Close()
+}
+
+func gzipDecoder(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// deflate 通常为 zlib 格式, 也兼容不带 zlib 头的原始 deflate 数据
+func deflateDecoder(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// CompressionConfig 请求体压缩配置
+type CompressionConfig struct {
+	Encoding string // gzip 或 deflate, 默认 gzip
+	MinSize  int    // 请求体达到该长度时才压缩, 默认 1024
+	Level    int    // 压缩级别, 默认 flate.DefaultCompression
+}
+
+// 请求体压缩
+type compressor struct {
+	encoding string
+	minSize  int
+	level    int
+}
+
+func newCompressor(config CompressionConfig) (*compressor, error) {
+	c := &compressor{encoding: strings.ToLower(config.Encoding), minSize: config.MinSize, level: config.Level}
+	if c.encoding == "" {
+		c.encoding = "gzip"
+	}
+	if c.encoding != "gzip" && c.encoding != "deflate" {
+		return nil, fmt.Errorf("unsupported request compression: %s", config.Encoding)
+	}
+	if c.minSize <= 0 {
+		c.minSize = 1024
+	}
+	if c.level == 0 {
+		c.level = flate.DefaultCompression
+	}
+	if c.level < flate.HuffmanOnly || c.level > flate.BestCompression {
+		return nil, fmt.Errorf("invalid compression level: %d", config.Level)
+	}
+	return c, nil
+}
+
+func (c *compressor) compress(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	if c.encoding == "gzip" {
+		w, err = gzip.NewWriterLevel(&buf, c.level)
+	} else {
+		w, err = zlib.NewWriterLevel(&buf, c.level)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// 压缩请求体并设置 Content-Encoding, 请求体过小或已设置 Content-Encoding 时不压缩
+func (c *compressor) apply(req *http.Request, body []byte) ([]byte, error) {
+	if len(body) < c.minSize || req.Header.Get("Content-Encoding") != "" {
+		return body, nil
+	}
+	compressed, err := c.compress(body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Encoding", c.encoding)
+	return compressed, nil
+}
+
+// 开启请求体压缩, 只作用于 []byte 请求体(Request、RequestWithContext、DoJSON 等), 不压缩流式请求体
+/* example
+client := network.NewClient(network.WithRequestCompression(network.CompressionConfig{MinSize: 4096}))
+*/
+func WithRequestCompression(config CompressionConfig) Option {
+	return func(c *Client) error {
+		compressor, err := newCompressor(config)
+		if err != nil {
+			return err
+		}
+		c.compressor = compressor
+		return nil
+	}
+}
+
+// 设置请求体压缩, 为 nil 时关闭
+func (c *Client) SetRequestCompression(config *CompressionConfig) error {
+	if config == nil {
+		c.compressor = nil
+		return nil
+	}
+	compressor, err := newCompressor(*config)
+	if err != nil {
+		return errors.NewClientError(errors.InvalidParamErrorCode, err.Error(), err)
+	}
+	c.compressor = compressor
+	return nil
+}
@@ -0,0 +1,179 @@
+package network
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func gzipBytes(data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+// 反转字节的测试编码
+func reverseDecoder(r io.Reader) (io.ReadCloser, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+		data[i], data[j] = data[j], data[i]
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// 测试按 Content-Encoding 解码响应体
+func TestClient_DecodeResponse(t *testing.T) {
+	payload := []byte(`{"message":"hello"}`)
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		var buf bytes.Buffer
+		switch r.URL.Path {
+		case "/gzip":
+			buf.Write(gzipBytes(payload))
+			w.Header().Set("Content-Encoding", "gzip")
+		case "/zlib":
+			zw := zlib.NewWriter(&buf)
+			zw.Write(payload)
+			zw.Close()
+			w.Header().Set("Content-Encoding", "deflate")
+		case "/deflate":
+			// 不带 zlib 头的原始 deflate
+			fw, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+			fw.Write(payload)
+			fw.Close()
+			w.Header().Set("Content-Encoding", "deflate")
+		case "/stacked":
+			// 先 rev 再 gzip
+			reversed, _ := reverseDecoder(bytes.NewReader(payload))
+			data, _ := io.ReadAll(reversed)
+			buf.Write(gzipBytes(data))
+			w.Header().Set("Content-Encoding", "rev, gzip")
+		case "/unknown":
+			buf.Write(payload)
+			w.Header().Set("Content-Encoding", "zstd")
+		}
+		w.Write(buf.Bytes())
+	})
+	defer server.Close()
+
+	client := NewClient(WithDecoder("rev", reverseDecoder))
+
+	for _, path := range []string{"/gzip", "/zlib", "/deflate", "/stacked"} {
+		response, err := client.Request("GET", server.URL+path, nil, 0)
+		if err != nil || !bytes.Equal(response.ResponseBodyBytes, payload) {
+			t.Errorf("%s: expected decoded payload, got %v %q", path, err, response.ResponseBodyBytes)
+			continue
+		}
+		if response.OriginHTTPResponse.Header.Get("Content-Encoding") != "" {
+			t.Errorf("%s: expected Content-Encoding to be removed", path)
+		}
+	}
+
+	response, _ := client.Request("GET", server.URL+"/gzip", nil, 0)
+	if accept := response.OriginHTTPResponse.Header.Get("X-Accept-Encoding"); accept != "gzip, deflate, rev" {
+		t.Errorf("Unexpected Accept-Encoding '%s'", accept)
+	}
+
+	// 未注册的编码原样返回
+	response, _ = client.Request("GET", server.URL+"/unknown", nil, 0)
+	if !bytes.Equal(response.ResponseBodyBytes, payload) || response.OriginHTTPResponse.Header.Get("Content-Encoding") != "zstd" {
+		t.Errorf("Expected unknown encoding to be left as is, got %q", response.ResponseBodyBytes)
+	}
+
+	// 流式响应同样解码
+	stream, err := client.Stream(context.Background(), "GET", server.URL+"/gzip", nil)
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	defer stream.Close()
+	if data, _ := io.ReadAll(stream.Body); !bytes.Equal(data, payload) {
+		t.Errorf("Expected decoded stream, got %q", data)
+	}
+}
+
+// 测试未注册其他解码器时由 http.Transport 透明解压 gzip
+func TestClient_TransportDecompression(t *testing.T) {
+	payload := []byte(`{"message":"hello"}`)
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			t.Errorf("Unexpected Accept-Encoding '%s'", r.Header.Get("Accept-Encoding"))
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(gzipBytes(payload))
+	})
+	defer server.Close()
+
+	// 包装 Transport 的 RoundTripper 看到解压后的响应体
+	var seen []byte
+	client := NewClient()
+	transport := client.transport
+	client.SetTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		seen, _ = io.ReadAll(resp.Body)
+		resp.Body = io.NopCloser(bytes.NewReader(seen))
+		return resp, nil
+	}))
+
+	response, err := client.Request("GET", server.URL, nil, 0)
+	if err != nil || !bytes.Equal(response.ResponseBodyBytes, payload) {
+		t.Fatalf("Expected decoded payload, got %v %q", err, response.ResponseBodyBytes)
+	}
+	if !bytes.Equal(seen, payload) {
+		t.Errorf("Expected RoundTripper to see decoded body, got %q", seen)
+	}
+}
+
+// 测试请求体压缩
+func TestClient_RequestCompression(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		var reader io.Reader = r.Body
+		switch r.Header.Get("Content-Encoding") {
+		case "gzip":
+			reader, _ = gzip.NewReader(r.Body)
+		case "deflate":
+			reader, _ = zlib.NewReader(r.Body)
+		}
+		body, _ := io.ReadAll(reader)
+		w.Write([]byte(r.Header.Get("Content-Encoding") + ":" + string(body)))
+	})
+	defer server.Close()
+
+	large := strings.Repeat("a", 2048)
+	client := NewClient(WithRequestCompression(CompressionConfig{}))
+
+	response, err := client.Request("POST", server.URL, []byte(large), 0)
+	if err != nil || response.ToString() != "gzip:"+large {
+		t.Errorf("Expected gzip request body, got %v '%.20s'", err, response.ToString())
+	}
+
+	response, _ = client.Request("POST", server.URL, []byte("small"), 0)
+	if response.ToString() != ":small" {
+		t.Errorf("Expected small body not to be compressed, got '%s'", response.ToString())
+	}
+
+	client.SetRequestCompression(&CompressionConfig{Encoding: "deflate", MinSize: 1})
+	response, _ = client.Request("PUT", server.URL, []byte("small"), 0)
+	if response.ToString() != "deflate:small" {
+		t.Errorf("Expected deflate request body, got '%s'", response.ToString())
+	}
+
+	for _, config := range []CompressionConfig{{Encoding: "br"}, {Level: 42}} {
+		if err := client.SetRequestCompression(&config); err == nil {
+			t.Errorf("Expected error for %+v", config)
+		}
+	}
+}
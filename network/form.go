@@ -0,0 +1,192 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shzy2012/common/errors"
+)
+
+// 发起 x-www-form-urlencoded 请求, 与 RequestWithContext 共用重试、cookies 和认证
+// form 支持 url.Values、map[string]string、map[string][]string 以及带 form 标签的结构体
+/* example
+type Login struct {
+	User   string   `form:"user"`
+	Scopes []string `form:"scope"`
+	Remember bool   `form:"remember,omitempty"`
+}
+resp, err := client.PostFormValues(ctx, url, Login{User: "joey", Scopes: []string{"a", "b"}})
+*/
+func (c *Client) PostFormValues(ctx context.Context, url string, form interface{}) (*HTTPResponse, error) {
+	values, err := EncodeForm(form)
+	if err != nil {
+		return &HTTPResponse{}, err
+	}
+
+	return c.do(ctx, &request{
+		method: POST,
+		url:    url,
+		header: http.Header{ContentType: {XwwwFormUrlencoded}},
+		body:   []byte(values.Encode()),
+	})
+}
+
+// FormBody 将 form 编码为 x-www-form-urlencoded 请求体, 支持的类型同 EncodeForm
+func (b *RequestBuilder) FormBody(form interface{}) *RequestBuilder {
+	values, err := EncodeForm(form)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	return b.Body([]byte(values.Encode()), XwwwFormUrlencoded)
+}
+
+// EncodeForm 将 url.Values、map[string]string、map[string][]string 或结构体转换为 url.Values
+// 结构体字段使用 form 标签指定名称, "-" 表示忽略, omitempty 表示零值时忽略;
+// 切片和数组编码为重复的 key, 匿名结构体字段会被展开, time.Time 编码为 RFC3339
+func EncodeForm(form interface{}) (url.Values, error) {
+	switch v := form.(type) {
+	case nil:
+		return url.Values{}, nil
+	case url.Values:
+		return v, nil
+	case map[string][]string:
+		return url.Values(v), nil
+	case map[string]string:
+		values := url.Values{}
+		for key, value := range v {
+			values.Set(key, value)
+		}
+		return values, nil
+	}
+
+	rv := reflect.ValueOf(form)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return url.Values{}, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		msg := fmt.Sprintf("unsupported form type: %T", form)
+		return nil, errors.NewClientError(errors.InvalidParamErrorCode, msg, nil)
+	}
+
+	values := url.Values{}
+	if err := encodeStruct(values, rv); err != nil {
+		return nil, errors.NewClientError(errors.InvalidParamErrorCode, err.Error(), err)
+	}
+	return values, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func encodeStruct(values url.Values, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		tag := field.Tag.Get("form")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		omitempty := opts == "omitempty"
+
+		// 展开匿名结构体
+		if field.Anonymous && name == "" {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && fv.Type() != timeType {
+				if err := encodeStruct(values, fv); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if omitempty && fv.IsZero() {
+			continue
+		}
+
+		if (fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array) && fv.Type().Elem().Kind() != reflect.Uint8 {
+			for j := 0; j < fv.Len(); j++ {
+				s, err := formValue(fv.Index(j))
+				if err != nil {
+					return fmt.Errorf("form field %s: %w", field.Name, err)
+				}
+				values.Add(name, s)
+			}
+			continue
+		}
+
+		s, err := formValue(fv)
+		if err != nil {
+			return fmt.Errorf("form field %s: %w", field.Name, err)
+		}
+		values.Add(name, s)
+	}
+	return nil
+}
+
+// 单个值转换为字符串
+func formValue(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	if v.CanInterface() {
+		if t, ok := v.Interface().(time.Time); ok {
+			return t.Format(time.RFC3339), nil
+		}
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			return s.String(), nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case reflect.Slice:
+		// []byte
+		return string(v.Bytes()), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
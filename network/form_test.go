@@ -0,0 +1,117 @@
+package network
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	commonErrors "github.com/shzy2012/common/errors"
+)
+
+// 测试表单编码: 特殊字符、非 ASCII 和重复的 key
+func TestClient_PostFormValues(t *testing.T) {
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != XwwwFormUrlencoded {
+			t.Errorf("Unexpected Content-Type '%s'", r.Header.Get("Content-Type"))
+		}
+		if user, _, _ := r.BasicAuth(); user != "u" {
+			t.Errorf("Expected basic auth, got '%s'", user)
+		}
+		if c, err := r.Cookie("sid"); err != nil || c.Value != "s1" {
+			t.Errorf("Expected cookie, got %v", err)
+		}
+
+		// 第一次返回 503 测试重试
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	})
+	defer server.Close()
+
+	client := NewClient(WithBasicAuth("u", "p"), WithRetryPolicy(&ExponentialBackoff{MaxRetries: 1}))
+	client.SetCookie(&http.Cookie{Name: "sid", Value: "s1"})
+
+	values := url.Values{"q": {"a&b=c 100%"}, "name": {"中文"}, "tag": {"x", "y"}}
+	response, err := client.PostFormValues(context.Background(), server.URL, values)
+	if err != nil {
+		t.Fatalf("PostFormValues failed: %v", err)
+	}
+
+	if response.ToString() != values.Encode() {
+		t.Errorf("Expected '%s', got '%s'", values.Encode(), response.ToString())
+	}
+
+	// PostForm2 使用相同的编码
+	response, _ = client.PostForm2(server.URL, map[string]string{"k": "a+b c"})
+	if response.ToString() != "k=a%2Bb+c" {
+		t.Errorf("Unexpected PostForm2 body '%s'", response.ToString())
+	}
+}
+
+type formBase struct {
+	Tenant string `form:"tenant"`
+}
+
+type formLevel int
+
+func (l formLevel) String() string {
+	return "level-" + string(rune('0'+l))
+}
+
+// 测试结构体编码
+func TestEncodeForm(t *testing.T) {
+	count := 3
+	type request struct {
+		formBase
+		User     string    `form:"user"`
+		Scopes   []string  `form:"scope"`
+		Count    *int      `form:"count"`
+		Missing  *int      `form:"missing"`
+		Ratio    float64   `form:"ratio"`
+		Remember bool      `form:"remember,omitempty"`
+		Since    time.Time `form:"since"`
+		Level    formLevel `form:"level"`
+		Secret   string    `form:"-"`
+		Plain    string
+		hidden   string
+	}
+
+	values, err := EncodeForm(&request{
+		formBase: formBase{Tenant: "t1"},
+		User:     "joey",
+		Scopes:   []string{"read", "write"},
+		Count:    &count,
+		Ratio:    0.5,
+		Since:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:    2,
+		Secret:   "s",
+		Plain:    "p",
+		hidden:   "h",
+	})
+	if err != nil {
+		t.Fatalf("EncodeForm failed: %v", err)
+	}
+
+	want := "Plain=p&count=3&level=level-2&ratio=0.5&scope=read&scope=write&since=2024-01-02T03%3A04%3A05Z&tenant=t1&user=joey"
+	if values.Encode() != want {
+		t.Errorf("Expected '%s', got '%s'", want, values.Encode())
+	}
+
+	_, err = EncodeForm(42)
+	if e, ok := err.(commonErrors.Error); !ok || e.ErrorCode() != commonErrors.InvalidParamErrorCode {
+		t.Errorf("Expected InvalidParam error, got %v", err)
+	}
+
+	_, err = EncodeForm(struct{ C chan int }{})
+	if err == nil {
+		t.Error("Expected error for unsupported field type")
+	}
+}
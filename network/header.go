@@ -0,0 +1,87 @@
+package network
+
+import (
+	"context"
+	"net/http"
+)
+
+type requestHeaderKey struct{}
+
+// 返回携带请求header的 Context, 只作用于使用该 Context 发起的请求, 不修改客户端
+// 多次调用时合并, 同名header以最后一次为准
+/* example
+ctx := network.ContextWithHeader(ctx, map[string]string{"X-Tenant": "t1"})
+network.GetWithContext(ctx, url)
+*/
+func ContextWithHeader(ctx context.Context, header map[string]string) context.Context {
+	merged := http.Header{}
+	if parent, ok := ctx.Value(requestHeaderKey{}).(http.Header); ok {
+		for k, v := range parent {
+			merged[k] = v
+		}
+	}
+	for k, v := range header {
+		merged.Set(k, v)
+	}
+	return context.WithValue(ctx, requestHeaderKey{}, merged)
+}
+
+// 获取 Context 中的请求header
+func HeaderFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(requestHeaderKey{}).(http.Header)
+	return header
+}
+
+// 设置header(并发安全)
+func (c *Client) SetHeader(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Header[key] = value
+}
+
+// 删除header(并发安全)
+func (c *Client) DelHeader(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.Header, key)
+}
+
+// 获取header(并发安全)
+func (c *Client) GetHeader(key string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Header[key]
+}
+
+// 获取所有header的副本(并发安全)
+func (c *Client) Headers() map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	header := make(map[string]string, len(c.Header))
+	for k, v := range c.Header {
+		header[k] = v
+	}
+	return header
+}
+
+// 获取所有cookie的副本(并发安全)
+func (c *Client) GetCookies() []*http.Cookie {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	cookies := make([]*http.Cookie, len(c.Cookies))
+	copy(cookies, c.Cookies)
+	return cookies
+}
+
+// 为请求设置客户端header, 再用 Context 中的header覆盖
+func (c *Client) setHeader(req *http.Request) {
+	c.mu.RLock()
+	for k, v := range c.Header {
+		req.Header.Set(k, v)
+	}
+	c.mu.RUnlock()
+
+	for k, v := range HeaderFromContext(req.Context()) {
+		req.Header[k] = v
+	}
+}
@@ -0,0 +1,105 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// 测试 Context 中的请求header
+func TestContextWithHeader(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", r.Header.Get("X-Tenant"), r.Header.Get("X-Request"))
+	})
+	defer server.Close()
+
+	client := NewClient(WithHeader("X-Tenant", "t1"))
+
+	ctx := ContextWithHeader(context.Background(), map[string]string{"X-Tenant": "t2"})
+	ctx = ContextWithHeader(ctx, map[string]string{"X-Request": "r1"})
+
+	response, err := client.RequestWithContext(ctx, "GET", server.URL, nil, 0)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if response.ToString() != "t2 r1" {
+		t.Errorf("Expected 't2 r1', got '%s'", response.ToString())
+	}
+
+	if client.GetHeader("X-Tenant") != "t1" {
+		t.Error("Request header should not modify client header")
+	}
+
+	// 不带 Context header 的请求使用客户端header
+	response, _ = client.Request("GET", server.URL, nil, 0)
+	if response.ToString() != "t1 " {
+		t.Errorf("Expected 't1 ', got '%s'", response.ToString())
+	}
+}
+
+// 测试 header 操作
+func TestClient_HeaderOperations(t *testing.T) {
+	client := NewClient()
+	client.SetHeader("X-A", "a")
+
+	headers := client.Headers()
+	headers["X-B"] = "b"
+
+	if client.GetHeader("X-A") != "a" || client.GetHeader("X-B") != "" {
+		t.Error("Headers() should return a copy")
+	}
+
+	client.DelHeader("X-A")
+	if client.GetHeader("X-A") != "" {
+		t.Error("DelHeader failed")
+	}
+}
+
+// 并发使用同一个客户端, 需配合 go test -race 运行
+func TestClient_ConcurrentHeaderAndCookie(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	client := NewClient()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(4)
+		go func(i int) {
+			defer wg.Done()
+			ctx := ContextWithHeader(context.Background(), map[string]string{"X-Seq": fmt.Sprint(i)})
+			if _, err := client.RequestWithContext(ctx, "GET", server.URL, nil, 0); err != nil {
+				t.Errorf("Request failed: %v", err)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			client.SetHeader("X-Worker", fmt.Sprint(i))
+			client.DelHeader("X-Worker")
+			_ = client.Headers()
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			client.SetCookie(&http.Cookie{Name: fmt.Sprintf("c%d", i), Value: "v"})
+			_ = client.GetCookies()
+			if i%3 == 0 {
+				client.ClearCookies()
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			clone := client.Clone()
+			clone.SetHeader("X-Clone", "1")
+		}()
+	}
+	wg.Wait()
+
+	if _, ok := client.Headers()["X-Clone"]; ok {
+		t.Error("Expected clone header not to leak into the original client")
+	}
+}
@@ -0,0 +1,291 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shzy2012/common/errors"
+)
+
+// HedgeConfig 对冲请求配置
+// 幂等请求(GET、HEAD、PUT 等)在等待 Delay 后仍未返回时再发送一个相同的请求, 使用最先返回的响应并取消其余请求
+// 请求体无法重放的请求和流式请求(Stream、SSE、Download)不对冲; 对冲请求分别经过限流和熔断, 不重新执行请求拦截器
+/* example
+client := network.NewClient(network.WithHedging(network.HedgeConfig{
+	Delay:      50 * time.Millisecond,
+	Percentile: 95,
+}))
+*/
+type HedgeConfig struct {
+	Delay      time.Duration // 发送对冲请求前的等待时间, 开启 Percentile 且样本不足时使用
+	Percentile float64       // 按该 host 最近请求耗时的百分位数作为等待时间, 例如 95 表示 p95, 为 0 时只使用 Delay
+	MaxHedges  int           // 最多额外发送的请求数, 默认 1
+}
+
+// HedgeStats 对冲请求统计
+type HedgeStats struct {
+	Requests int64 // 可对冲的请求数
+	Hedged   int64 // 额外发送的请求数
+	Wins     int64 // 对冲请求先于原请求返回的次数
+}
+
+const (
+	hedgeSamples    = 128 // 每个 host 保留的耗时样本数
+	hedgeMinSamples = 20  // 按百分位数计算等待时间所需的最少样本数
+)
+
+// 对冲请求
+type hedger struct {
+	config HedgeConfig
+
+	mu        sync.Mutex
+	latencies map[string]*latencyWindow
+
+	requests int64
+	hedged   int64
+	wins     int64
+}
+
+// 最近请求耗时的环形缓冲
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+func newHedger(config HedgeConfig) (*hedger, error) {
+	if config.Delay < 0 || config.Percentile < 0 || config.Percentile >= 100 || config.MaxHedges < 0 {
+		return nil, fmt.Errorf("invalid hedge config: %+v", config)
+	}
+	if config.Delay == 0 && config.Percentile == 0 {
+		return nil, fmt.Errorf("hedge config requires Delay or Percentile")
+	}
+	if config.MaxHedges == 0 {
+		config.MaxHedges = 1
+	}
+	return &hedger{config: config, latencies: map[string]*latencyWindow{}}, nil
+}
+
+// 幂等且请求体可以重放的请求才能对冲
+func (h *hedger) eligible(req *http.Request) bool {
+	if !IsIdempotent(strings.ToUpper(req.Method)) {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// 发送对冲请求前的等待时间
+func (h *hedger) delay(host string) time.Duration {
+	if h.config.Percentile == 0 {
+		return h.config.Delay
+	}
+
+	h.mu.Lock()
+	w := h.latencies[host]
+	if w == nil || len(w.samples) < hedgeMinSamples {
+		h.mu.Unlock()
+		return h.config.Delay
+	}
+	samples := append([]time.Duration(nil), w.samples...)
+	h.mu.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	i := int(math.Ceil(h.config.Percentile/100*float64(len(samples)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return samples[i]
+}
+
+// 记录请求耗时
+func (h *hedger) observe(host string, d time.Duration) {
+	if h.config.Percentile == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	w := h.latencies[host]
+	if w == nil {
+		w = &latencyWindow{}
+		h.latencies[host] = w
+	}
+	if len(w.samples) < hedgeSamples {
+		w.samples = append(w.samples, d)
+		return
+	}
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % hedgeSamples
+}
+
+// 单个请求的结果
+type hedgeResult struct {
+	index  int
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+	took   time.Duration
+}
+
+// 可以作为最终结果的响应
+func (r hedgeResult) ok() bool {
+	return r.err == nil && r.resp != nil && r.resp.StatusCode < 500
+}
+
+// 发送请求, 超过等待时间仍未返回时发送对冲请求, 返回最先成功的响应
+// 已发出的请求都失败时返回最后一个结果, 不会因为请求失败提前发送对冲请求
+// send 的 hedged 表示是否为对冲请求
+func (h *hedger) send(req *http.Request, send func(r *http.Request, hedged bool) (*http.Response, error)) (*http.Response, error) {
+	atomic.AddInt64(&h.requests, 1)
+
+	results := make(chan hedgeResult, h.config.MaxHedges+1)
+	var cancels []context.CancelFunc
+	launch := func(r *http.Request) {
+		ctx, cancel := context.WithCancel(req.Context())
+		cancels = append(cancels, cancel)
+		index := len(cancels) - 1
+		r = r.WithContext(ctx)
+
+		go func() {
+			start := time.Now()
+			resp, err := send(r, index > 0)
+			results <- hedgeResult{index: index, resp: resp, err: err, cancel: cancel, took: time.Since(start)}
+		}()
+	}
+
+	// 发送前复制请求, 发送中的请求会被修改(例如 cookie jar 添加 cookie), 不能再复制
+	template := req.Clone(req.Context())
+
+	// 对冲请求使用请求的副本和新的请求体
+	hedge := func() bool {
+		r := template.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return false
+			}
+			r.Body = body
+		}
+		atomic.AddInt64(&h.hedged, 1)
+		launch(r)
+		return true
+	}
+
+	delay := h.delay(req.URL.Host)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	launch(req)
+	inflight := 1
+	var last *hedgeResult
+	for {
+		select {
+		case <-timer.C:
+			if len(cancels) <= h.config.MaxHedges && hedge() {
+				inflight++
+				timer.Reset(delay)
+			}
+
+		case res := <-results:
+			inflight--
+			if res.ok() {
+				h.observe(req.URL.Host, res.took)
+				if res.index > 0 {
+					atomic.AddInt64(&h.wins, 1)
+				}
+				h.discard(cancels, res.index, results, inflight)
+				// 响应体关闭时才取消胜出请求的 Context
+				res.resp.Body = &cancelReadCloser{ReadCloser: res.resp.Body, cancel: res.cancel}
+				return res.resp, nil
+			}
+
+			if last != nil {
+				last.close()
+			}
+			last = &res
+
+			// 发出的请求都已失败时返回最后的结果, 由重试策略决定是否重试
+			if inflight == 0 {
+				if last.resp != nil {
+					last.resp.Body = &cancelReadCloser{ReadCloser: last.resp.Body, cancel: last.cancel}
+				} else {
+					last.cancel()
+				}
+				return last.resp, last.err
+			}
+		}
+	}
+}
+
+// 取消其余请求, 并在后台关闭它们的响应
+func (h *hedger) discard(cancels []context.CancelFunc, winner int, results chan hedgeResult, inflight int) {
+	for i, cancel := range cancels {
+		if i != winner {
+			cancel()
+		}
+	}
+	if inflight == 0 {
+		return
+	}
+	go func() {
+		for i := 0; i < inflight; i++ {
+			res := <-results
+			res.close()
+		}
+	}()
+}
+
+func (r *hedgeResult) close() {
+	if r.resp != nil && r.resp.Body != nil {
+		r.resp.Body.Close()
+	}
+	r.cancel()
+}
+
+func (h *hedger) stats() HedgeStats {
+	return HedgeStats{
+		Requests: atomic.LoadInt64(&h.requests),
+		Hedged:   atomic.LoadInt64(&h.hedged),
+		Wins:     atomic.LoadInt64(&h.wins),
+	}
+}
+
+// 开启对冲请求
+func WithHedging(config HedgeConfig) Option {
+	return func(c *Client) error {
+		h, err := newHedger(config)
+		if err != nil {
+			return err
+		}
+		c.hedge = h
+		return nil
+	}
+}
+
+// 设置对冲请求, 为 nil 时关闭
+func (c *Client) SetHedging(config *HedgeConfig) error {
+	if config == nil {
+		c.hedge = nil
+		return nil
+	}
+	h, err := newHedger(*config)
+	if err != nil {
+		return errors.NewClientError(errors.InvalidParamErrorCode, err.Error(), err)
+	}
+	c.hedge = h
+	return nil
+}
+
+// 获取对冲请求统计, 未开启时返回零值
+func (c *Client) HedgeStats() HedgeStats {
+	if c.hedge == nil {
+		return HedgeStats{}
+	}
+	return c.hedge.stats()
+}
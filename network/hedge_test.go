@@ -0,0 +1,235 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 测试慢请求被对冲请求取代, 并取消原请求
+func TestClient_Hedging(t *testing.T) {
+	var calls int32
+	cancelled := make(chan struct{}, 1)
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+				cancelled <- struct{}{}
+			case <-time.After(2 * time.Second):
+			}
+			return
+		}
+		w.Write([]byte("fast"))
+	})
+	defer server.Close()
+
+	client := NewClient(WithHedging(HedgeConfig{Delay: 20 * time.Millisecond}))
+
+	start := time.Now()
+	response, err := client.Request("GET", server.URL, nil, 0)
+	if err != nil || response.ToString() != "fast" {
+		t.Fatalf("Expected hedged response, got %v '%s'", err, response.ToString())
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected hedge to cut latency, took %s", time.Since(start))
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("Expected slow request to be cancelled")
+	}
+
+	if stats := client.HedgeStats(); stats.Requests != 1 || stats.Hedged != 1 || stats.Wins != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	// 非幂等请求不对冲
+	atomic.StoreInt32(&calls, 1)
+	client.Request("POST", server.URL, []byte("x"), 0)
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("Expected POST not to be hedged, got %d calls", n-1)
+	}
+}
+
+// 测试请求快速失败时不发送对冲请求, 由重试策略处理
+func TestClient_Hedging_Failure(t *testing.T) {
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+
+	client := NewClient(WithHedging(HedgeConfig{Delay: 50 * time.Millisecond, MaxHedges: 2}))
+
+	response, err := client.Request("GET", server.URL, nil, 0)
+	if err == nil || response.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503, got %v %d", err, response.StatusCode)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Expected fast failure not to be hedged, got %d calls", n)
+	}
+	if stats := client.HedgeStats(); stats.Hedged != 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	// 所有请求都失败时返回最后的响应
+	var failures int32
+	failing := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&failures, 1)
+		// 对冲请求使用重新生成的请求体
+		if body, _ := io.ReadAll(r.Body); string(body) != "payload" {
+			t.Errorf("Unexpected body '%s'", body)
+		}
+		time.Sleep(30 * time.Millisecond)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer failing.Close()
+
+	client.SetHedging(&HedgeConfig{Delay: 10 * time.Millisecond})
+	response, err = client.Request("PUT", failing.URL, []byte("payload"), 0)
+	if err == nil || response.StatusCode != http.StatusBadGateway || atomic.LoadInt32(&failures) != 2 {
+		t.Errorf("Expected last failure after 2 calls, got %v %d after %d calls", err, response.StatusCode, failures)
+	}
+}
+
+// 测试按百分位数计算等待时间
+func TestHedger_Percentile(t *testing.T) {
+	h, _ := newHedger(HedgeConfig{Delay: time.Second, Percentile: 90})
+
+	if d := h.delay("a"); d != time.Second {
+		t.Errorf("Expected fallback delay without samples, got %s", d)
+	}
+
+	for i := 1; i <= 100; i++ {
+		h.observe("a", time.Duration(i)*time.Millisecond)
+	}
+	if d := h.delay("a"); d != 90*time.Millisecond {
+		t.Errorf("Expected p90 of 90ms, got %s", d)
+	}
+
+	// 环形缓冲只保留最近的样本
+	for i := 0; i < hedgeSamples; i++ {
+		h.observe("a", 5*time.Millisecond)
+	}
+	if d := h.delay("a"); d != 5*time.Millisecond {
+		t.Errorf("Expected recent samples only, got %s", d)
+	}
+
+	for _, config := range []HedgeConfig{{}, {Delay: -1}, {Percentile: 100}, {Delay: time.Second, MaxHedges: -1}} {
+		if err := NewClient().SetHedging(&config); err == nil {
+			t.Errorf("Expected error for %+v", config)
+		}
+	}
+}
+
+// 测试调用方取消时所有请求都被取消
+func TestClient_Hedging_Cancel(t *testing.T) {
+	var active int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		<-r.Context().Done()
+	})
+	defer server.Close()
+
+	client := NewClient(WithHedging(HedgeConfig{Delay: 10 * time.Millisecond, MaxHedges: 2}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err := client.RequestWithContext(ctx, "GET", server.URL, nil, 0)
+	if err == nil || !strings.Contains(fmt.Sprint(err), "context deadline exceeded") {
+		t.Errorf("Expected deadline error, got %v", err)
+	}
+
+	if stats := client.HedgeStats(); stats.Hedged != 2 {
+		t.Errorf("Expected 2 hedges, got %+v", stats)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&active) != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&active); n != 0 {
+		t.Errorf("Expected all requests to be cancelled, %d still active", n)
+	}
+}
+
+// 测试对冲请求与 cookie jar 并发修改请求头
+func TestClient_Hedging_CookieJar(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte("ok"))
+	})
+	defer server.Close()
+
+	client := NewClient(WithHedging(HedgeConfig{Delay: time.Microsecond, MaxHedges: 2}))
+	client.SetHeader("X-Test", "1")
+	for i := 0; i < 20; i++ {
+		if response, err := client.Request("GET", server.URL, nil, 0); err != nil || response.ToString() != "ok" {
+			t.Fatalf("Request failed: %v", err)
+		}
+	}
+}
+
+// 测试对冲请求分别经过限流和熔断, 流式请求不对冲
+func TestClient_Hedging_Admission(t *testing.T) {
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		time.Sleep(80 * time.Millisecond)
+		w.Write([]byte("ok"))
+	})
+	defer server.Close()
+
+	// 令牌用完时对冲请求等待限流
+	client := NewClient(
+		WithHedging(HedgeConfig{Delay: 10 * time.Millisecond}),
+		WithRateLimit(RateLimitConfig{Default: RateLimit{Rate: 0.1, Burst: 1}}),
+	)
+	if response, err := client.Request("GET", server.URL, nil, 0); err != nil || response.ToString() != "ok" {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Expected hedge to wait for the rate limiter, got %d calls", n)
+	}
+
+	// 半开状态只放行一个探测请求, 对冲请求被熔断器拒绝
+	client = NewClient(
+		WithHedging(HedgeConfig{Delay: 10 * time.Millisecond}),
+		WithCircuitBreaker(CircuitBreakerConfig{MinRequests: 1, Cooldown: 50 * time.Millisecond}),
+	)
+	client.Request("GET", server.URL+"/fail", nil, 0)
+	time.Sleep(60 * time.Millisecond)
+	atomic.StoreInt32(&calls, 0)
+	if response, err := client.Request("GET", server.URL, nil, 0); err != nil || response.ToString() != "ok" {
+		t.Fatalf("Expected probe to succeed, got %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Expected hedge to be rejected in half-open state, got %d calls", n)
+	}
+
+	// 流式请求不对冲
+	atomic.StoreInt32(&calls, 0)
+	stream, err := client.Stream(context.Background(), "GET", server.URL, nil)
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	io.ReadAll(stream.Body)
+	stream.Close()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Expected stream not to be hedged, got %d calls", n)
+	}
+}
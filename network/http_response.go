@@ -1,7 +1,17 @@
 package network
 
 import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shzy2012/common/errors"
 )
 
 // HTTPResponse http响应
@@ -11,9 +21,123 @@ type HTTPResponse struct {
 	Message            string
 	ResponseBodyBytes  []byte
 	OriginHTTPResponse *http.Response
+	Header             http.Header    // 响应头
+	Cookies            []*http.Cookie // 响应设置的 cookie
+	FinalURL           string         // 重定向后的最终地址
+	Elapsed            time.Duration  // 调用总耗时, 包括重试和退避
+	Attempts           int            // 发送次数, 包括重试; 命中缓存未发送时为 0
+}
+
+// 记录原始响应的状态、响应头、cookie 和最终地址
+func (r *HTTPResponse) setOrigin(resp *http.Response) {
+	r.StatusCode = resp.StatusCode
+	r.Status = resp.Status
+	r.OriginHTTPResponse = resp // 原始的Http Response
+	r.Header = resp.Header
+	r.Cookies = resp.Cookies()
+	if resp.Request != nil && resp.Request.URL != nil {
+		r.FinalURL = resp.Request.URL.String()
+	}
 }
 
 // ToString 将http body转化为字符串
 func (r *HTTPResponse) ToString() string {
 	return string(r.ResponseBodyBytes)
 }
+
+// IsSuccess 状态码是否为 2xx
+func (r *HTTPResponse) IsSuccess() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// ContentType 响应的媒体类型(小写, 不含参数), 例如 application/json
+func (r *HTTPResponse) ContentType() string {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get(ContentType))
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+// Charset 响应的字符集(小写), 优先使用 Content-Type 中的 charset,
+// 其次根据 BOM、HTML meta 和 XML 声明识别; 无法识别时合法的 UTF-8 返回 utf-8, 否则返回空字符串
+func (r *HTTPResponse) Charset() string {
+	if _, params, err := mime.ParseMediaType(r.Header.Get(ContentType)); err == nil && params["charset"] != "" {
+		return strings.ToLower(strings.Trim(params["charset"], `"' `))
+	}
+	return sniffCharset(r.ResponseBodyBytes)
+}
+
+// Bytes 转换为 UTF-8 的响应体, 内置 UTF-8、ISO-8859-1、UTF-16、GBK(GB2312) 和 GB18030
+// 其他字符集需要通过 RegisterCharset 注册
+/* example
+body, err := resp.Bytes() // Content-Type: text/html; charset=GBK
+*/
+func (r *HTTPResponse) Bytes() ([]byte, error) {
+	charset := r.Charset()
+	body, err := toUTF8(r.ResponseBodyBytes, charset)
+	if err != nil {
+		errMsg := fmt.Sprintf(errors.UnsupportedCharsetErrorMessage, charset)
+		return nil, errors.NewClientError(errors.UnsupportedCharsetErrorCode, errMsg, err)
+	}
+	return body, nil
+}
+
+// Text 转换为 UTF-8 的响应体字符串
+func (r *HTTPResponse) Text() (string, error) {
+	body, err := r.Bytes()
+	return string(body), err
+}
+
+// Lines 按行拆分转换为 UTF-8 的响应体, 兼容 \r\n, 忽略末尾的空行
+// 字符集不支持时按原始字节拆分
+func (r *HTTPResponse) Lines() []string {
+	body, err := r.Bytes()
+	if err != nil {
+		body = r.ResponseBodyBytes
+	}
+
+	text := strings.TrimSuffix(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// DecodeJSON 将响应体解析为 JSON
+func (r *HTTPResponse) DecodeJSON(v interface{}) error {
+	if err := json.Unmarshal(r.ResponseBodyBytes, v); err != nil {
+		return errors.NewClientError(errors.JsonUnmarshalErrorCode, errors.JsonUnmarshalErrorMessage, err)
+	}
+	return nil
+}
+
+// JSON 将响应体转换为 UTF-8 后解析为 JSON
+func (r *HTTPResponse) JSON(v interface{}) error {
+	body, err := r.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return errors.NewClientError(errors.JsonUnmarshalErrorCode, errors.JsonUnmarshalErrorMessage, err)
+	}
+	return nil
+}
+
+// XML 将响应体转换为 UTF-8 后解析为 XML, 忽略 XML 声明中的 encoding
+func (r *HTTPResponse) XML(v interface{}) error {
+	body, err := r.Bytes()
+	if err != nil {
+		return err
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	// 已转换为 UTF-8
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	if err := decoder.Decode(v); err != nil {
+		return errors.NewClientError(errors.XmlUnmarshalErrorCode, errors.XmlUnmarshalErrorMessage, err)
+	}
+	return nil
+}
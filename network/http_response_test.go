@@ -1,8 +1,14 @@
 package network
 
 import (
+	"bytes"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/shzy2012/common/errors"
 )
 
 func TestHTTPResponse_ToString(t *testing.T) {
@@ -165,3 +171,213 @@ func BenchmarkHTTPResponse_ToString(b *testing.B) {
 		_ = response.ToString()
 	}
 }
+
+// 测试响应头、cookie、最终地址、耗时和发送次数
+func TestHTTPResponse_Origin(t *testing.T) {
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/final", http.StatusFound)
+		case "/final":
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+			w.Header().Set("X-Test", "yes")
+			w.Write([]byte("ok"))
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(WithRetryPolicy(&ExponentialBackoff{MaxRetries: 1, InitialInterval: 10 * time.Millisecond}))
+	response, err := client.Request("GET", server.URL+"/redirect", nil, 0)
+	if err != nil || !response.IsSuccess() {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if response.Header.Get("X-Test") != "yes" {
+		t.Errorf("Expected response header, got %v", response.Header)
+	}
+	if len(response.Cookies) != 1 || response.Cookies[0].Name != "session" || response.Cookies[0].Value != "abc" {
+		t.Errorf("Unexpected cookies %v", response.Cookies)
+	}
+	if response.FinalURL != server.URL+"/final" {
+		t.Errorf("Expected final URL %s/final, got %s", server.URL, response.FinalURL)
+	}
+	if response.Attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", response.Attempts)
+	}
+	if response.Elapsed < 10*time.Millisecond {
+		t.Errorf("Expected elapsed to include backoff, got %s", response.Elapsed)
+	}
+}
+
+func TestHTTPResponse_IsSuccess(t *testing.T) {
+	for status, want := range map[int]bool{199: false, 200: true, 204: true, 299: true, 301: false, 404: false, 500: false} {
+		if got := (&HTTPResponse{StatusCode: status}).IsSuccess(); got != want {
+			t.Errorf("IsSuccess() for %d = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestHTTPResponse_Charset(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		mediaType   string
+		charset     string
+	}{
+		{"header", "Text/HTML; charset=\"GBK\"", []byte("x"), "text/html", "gbk"},
+		{"meta", "text/html", []byte(`<html><head><meta charset="gb2312"></head></html>`), "text/html", "gb2312"},
+		{"http-equiv", "", []byte(`<meta http-equiv="Content-Type" content="text/html; charset=ISO-8859-1">`), "", "iso-8859-1"},
+		{"xml", "application/xml", []byte(`<?xml version="1.0" encoding="GB18030"?><a/>`), "application/xml", "gb18030"},
+		{"utf-8 bom", "", []byte("\xef\xbb\xbfhi"), "", "utf-8"},
+		{"utf-16 bom", "", []byte{0xff, 0xfe, 'h', 0}, "", "utf-16le"},
+		{"valid utf-8", "application/json", []byte(`{"a":"你好"}`), "application/json", "utf-8"},
+		{"unknown", "", []byte{0xc4, 0xe3, 0xff}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &HTTPResponse{Header: http.Header{}, ResponseBodyBytes: tt.body}
+			if tt.contentType != "" {
+				response.Header.Set(ContentType, tt.contentType)
+			}
+			if got := response.ContentType(); got != tt.mediaType {
+				t.Errorf("ContentType() = %q, want %q", got, tt.mediaType)
+			}
+			if got := response.Charset(); got != tt.charset {
+				t.Errorf("Charset() = %q, want %q", got, tt.charset)
+			}
+		})
+	}
+}
+
+// 测试内置字符集的转换
+func TestHTTPResponse_Text(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		want        string
+	}{
+		{"utf-8 bom", "text/plain", []byte("\xef\xbb\xbfhello"), "hello"},
+		{"latin1", "text/plain; charset=ISO-8859-1", []byte{'c', 'a', 'f', 0xe9}, "café"},
+		{"utf-16le bom", "", []byte{0xff, 0xfe, 'h', 0, 'i', 0}, "hi"},
+		{"utf-16be", "text/plain; charset=utf-16be", []byte{0, 'h', 0, 'i'}, "hi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &HTTPResponse{Header: http.Header{ContentType: {tt.contentType}}, ResponseBodyBytes: tt.body}
+			if got, err := response.Text(); err != nil || got != tt.want {
+				t.Errorf("Text() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// 测试内置的 GBK、GB2312 和 GB18030 解码
+func TestHTTPResponse_GBK(t *testing.T) {
+	gbk := []byte{0xc4, 0xe3, 0xba, 0xc3} // GBK 编码的 "你好"
+	response := &HTTPResponse{Header: http.Header{}, ResponseBodyBytes: gbk}
+
+	for _, charset := range []string{"gb2312", "GBK", "cp936", "x-gbk", "gb18030"} {
+		response.Header.Set(ContentType, "text/plain; charset="+charset)
+		if text, err := response.Text(); err != nil || text != "你好" {
+			t.Errorf("%s: expected decoded text, got %q %v", charset, text, err)
+		}
+	}
+
+	// GB18030 的四字节编码
+	response = &HTTPResponse{
+		Header:            http.Header{ContentType: {"text/plain; charset=GB18030"}},
+		ResponseBodyBytes: []byte{0xd6, 0xd0, 0xce, 0xc4, 0x94, 0x39, 0xfc, 0x36},
+	}
+	if text, err := response.Text(); err != nil || text != "中文😀" {
+		t.Errorf("Expected GB18030 text, got %q %v", text, err)
+	}
+
+	response = &HTTPResponse{
+		Header:            http.Header{ContentType: {"text/plain; charset=gbk"}},
+		ResponseBodyBytes: append(append(append([]byte{}, gbk...), "\r\n"...), 0xd6, 0xd0, 0xce, 0xc4, '\n'),
+	}
+	if lines := response.Lines(); strings.Join(lines, "|") != "你好|中文" {
+		t.Errorf("Expected decoded lines, got %q", lines)
+	}
+
+	// XML 声明中的 encoding 不再重复解码
+	response = &HTTPResponse{
+		Header:            http.Header{},
+		ResponseBodyBytes: append([]byte(`<?xml version="1.0" encoding="GBK"?><msg><text>`), append(gbk, []byte(`</text></msg>`)...)...),
+	}
+	var msg struct {
+		Text string `xml:"text"`
+	}
+	if err := response.XML(&msg); err != nil || msg.Text != "你好" {
+		t.Errorf("Expected decoded XML, got %+v %v", msg, err)
+	}
+
+	response = &HTTPResponse{
+		Header:            http.Header{ContentType: {"application/json; charset=gbk"}},
+		ResponseBodyBytes: append(append([]byte(`{"text":"`), gbk...), '"', '}'),
+	}
+	var data struct {
+		Text string `json:"text"`
+	}
+	if err := response.JSON(&data); err != nil || data.Text != "你好" {
+		t.Errorf("Expected decoded JSON, got %+v %v", data, err)
+	}
+}
+
+// 测试未支持的字符集以及注册字符集
+func TestHTTPResponse_RegisterCharset(t *testing.T) {
+	response := &HTTPResponse{
+		Header:            http.Header{ContentType: {"text/plain; charset=x-test"}},
+		ResponseBodyBytes: []byte("hello\nworld"),
+	}
+
+	_, err := response.Text()
+	if e, ok := err.(errors.Error); !ok || e.ErrorCode() != errors.UnsupportedCharsetErrorCode {
+		t.Fatalf("Expected UnsupportedCharset error, got %v", err)
+	}
+	if lines := response.Lines(); strings.Join(lines, "|") != "hello|world" {
+		t.Errorf("Expected raw lines when charset is unsupported, got %q", lines)
+	}
+
+	RegisterCharset("X-Test", func(data []byte) ([]byte, error) {
+		return bytes.ToUpper(data), nil
+	})
+	defer RegisterCharset("x-test", nil)
+
+	if text, err := response.Text(); err != nil || text != "HELLO\nWORLD" {
+		t.Errorf("Expected registered decoder to be used, got %q %v", text, err)
+	}
+}
+
+func TestHTTPResponse_Lines(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a\r\nb\r\n", []string{"a", "b"}},
+		{"a\n\nb\n", []string{"a", "", "b"}},
+	}
+
+	for _, tt := range tests {
+		response := &HTTPResponse{ResponseBodyBytes: []byte(tt.body)}
+		if got := response.Lines(); strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("Lines(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+
+	response := &HTTPResponse{ResponseBodyBytes: []byte(`{"a":`)}
+	var v interface{}
+	if e, ok := response.JSON(&v).(errors.Error); !ok || e.ErrorCode() != errors.JsonUnmarshalErrorCode {
+		t.Errorf("Expected JsonUnmarshal error")
+	}
+}
@@ -0,0 +1,53 @@
+package network
+
+import (
+	"net/http"
+)
+
+// RequestInterceptor 请求发送前的钩子, 可修改 *http.Request (添加header、签名等)
+// 返回错误时终止本次请求; 发生重试时每次发送前都会调用
+type RequestInterceptor func(req *http.Request) error
+
+// ResponseInterceptor 请求结束后的钩子, 可读取或修改 *HTTPResponse
+// err 为请求的错误, 返回值作为最终的错误返回给调用方
+// 请求在构建阶段失败时 req 为 nil
+type ResponseInterceptor func(req *http.Request, resp *HTTPResponse, err error) error
+
+// 有序的拦截器链
+type interceptors struct {
+	requests  []RequestInterceptor
+	responses []ResponseInterceptor
+}
+
+// 依次执行请求拦截器
+func (i *interceptors) request(req *http.Request) error {
+	for _, fn := range i.requests {
+		if err := fn(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 依次执行响应拦截器
+func (i *interceptors) response(req *http.Request, resp *HTTPResponse, err error) error {
+	for _, fn := range i.responses {
+		err = fn(req, resp, err)
+	}
+	return err
+}
+
+// 添加请求拦截器, 按添加顺序执行
+func (c *Client) UseRequestInterceptor(fns ...RequestInterceptor) {
+	c.interceptors.requests = append(c.interceptors.requests, fns...)
+}
+
+// 添加响应拦截器, 按添加顺序执行
+func (c *Client) UseResponseInterceptor(fns ...ResponseInterceptor) {
+	c.interceptors.responses = append(c.interceptors.responses, fns...)
+}
+
+// 清除所有拦截器
+func (c *Client) ClearInterceptors() {
+	c.interceptors = interceptors{}
+}
@@ -0,0 +1,119 @@
+package network
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// 测试拦截器链的执行顺序
+func TestClient_Interceptors(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Trace")))
+	})
+	defer server.Close()
+
+	client := NewClient()
+	client.UseRequestInterceptor(
+		func(req *http.Request) error {
+			req.Header.Set("X-Trace", "a")
+			return nil
+		},
+		func(req *http.Request) error {
+			req.Header.Set("X-Trace", req.Header.Get("X-Trace")+"b")
+			return nil
+		},
+	)
+
+	var seen []string
+	client.UseResponseInterceptor(func(req *http.Request, resp *HTTPResponse, err error) error {
+		seen = append(seen, req.Method+" "+resp.ToString())
+		return err
+	})
+
+	response, err := client.Request("GET", server.URL, nil, 0)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if response.ToString() != "ab" {
+		t.Errorf("Expected body 'ab', got '%s'", response.ToString())
+	}
+
+	if len(seen) != 1 || seen[0] != "GET ab" {
+		t.Errorf("Unexpected response interceptor calls: %v", seen)
+	}
+
+	// 表单请求同样经过拦截器
+	if _, err := client.PostForm2(server.URL, map[string]string{"k": "v"}); err != nil {
+		t.Fatalf("PostForm2 failed: %v", err)
+	}
+	if _, err := client.PostForm(server.URL, map[string]io.Reader{"k": strings.NewReader("v")}); err != nil {
+		t.Fatalf("PostForm failed: %v", err)
+	}
+
+	if len(seen) != 3 || seen[1] != "POST ab" || seen[2] != "POST ab" {
+		t.Errorf("Unexpected response interceptor calls: %v", seen)
+	}
+}
+
+// 测试请求拦截器返回错误以及响应拦截器改写错误
+func TestClient_Interceptors_Error(t *testing.T) {
+	called := false
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	defer server.Close()
+
+	client := NewClient()
+	signErr := errors.New("sign failed")
+	client.UseRequestInterceptor(func(req *http.Request) error {
+		return signErr
+	})
+
+	var got error
+	client.UseResponseInterceptor(func(req *http.Request, resp *HTTPResponse, err error) error {
+		got = err
+		return nil
+	})
+
+	if _, err := client.Request("GET", server.URL, nil, 2); err != nil {
+		t.Errorf("Expected error to be swallowed by interceptor, got %v", err)
+	}
+
+	if called {
+		t.Error("Request should not be sent when interceptor fails")
+	}
+
+	e, ok := got.(interface{ OriginError() error })
+	if !ok || !errors.Is(e.OriginError(), signErr) {
+		t.Errorf("Expected interceptor error, got %v", got)
+	}
+}
+
+// 测试自定义 RoundTripper 后连接池设置依然生效
+func TestClient_SetTransport_KeepPool(t *testing.T) {
+	client := NewClient()
+	pool := client.transport
+
+	client.SetTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return pool.RoundTrip(req)
+	}))
+	client.SetMaxIdleConns(7)
+
+	if pool.MaxIdleConns != 7 {
+		t.Errorf("Expected MaxIdleConns 7, got %d", pool.MaxIdleConns)
+	}
+
+	if stats := client.GetConnectionPoolStats(); stats["CurrentMaxIdleConns"] != 7 {
+		t.Errorf("Expected CurrentMaxIdleConns 7, got %v", stats["CurrentMaxIdleConns"])
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
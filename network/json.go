@@ -0,0 +1,136 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/shzy2012/common/errors"
+)
+
+// ErrorBody 非 2xx 响应时携带解析后错误响应体的服务端错误
+// 使用 errors.As(err, &e) 获取, e 的类型为 *ErrorBody[E]
+type ErrorBody[E any] struct {
+	Body E
+	err  errors.Error
+}
+
+func (e *ErrorBody[E]) Error() string {
+	return e.err.Error()
+}
+
+// HttpStatus HTTP 状态码
+func (e *ErrorBody[E]) HttpStatus() int {
+	return e.err.HttpStatus()
+}
+
+// ErrorCode 错误码
+func (e *ErrorBody[E]) ErrorCode() string {
+	return e.err.ErrorCode()
+}
+
+// Message HTTP 消息
+func (e *ErrorBody[E]) Message() string {
+	return e.err.Message()
+}
+
+// String HTTP 错误消息
+func (e *ErrorBody[E]) String() string {
+	return e.err.String()
+}
+
+// OriginError HTTP 原始错误信息
+func (e *ErrorBody[E]) OriginError() error {
+	return e.err.OriginError()
+}
+
+// Unwrap 返回原始的服务端错误
+func (e *ErrorBody[E]) Unwrap() error {
+	return e.err
+}
+
+/* 发起 JSON 请求, 并将响应体解析为 Resp
+* ctx: Context
+* c:客户端, 为 nil 时使用 HTTP
+* method:POST\GET\PUT\PATCH\DELETE\HEAD\OPTIONS\TRACE\CONNECT
+* url:请求地址
+* req:请求参数, 为 nil 时不发送请求体
+ */
+func DoJSON[Req, Resp any](ctx context.Context, c *Client, method, url string, req Req) (Resp, error) {
+	var out Resp
+
+	response, err := doJSON(ctx, c, method, url, req)
+	if err != nil {
+		return out, err
+	}
+
+	if len(response.ResponseBodyBytes) == 0 {
+		return out, nil
+	}
+
+	err = response.DecodeJSON(&out)
+	return out, err
+}
+
+/* 发起 JSON 请求, 非 2xx 时将响应体解析为 E 并以 *ErrorBody[E] 返回
+* ctx: Context
+* c:客户端, 为 nil 时使用 HTTP
+* method:POST\GET\PUT\PATCH\DELETE\HEAD\OPTIONS\TRACE\CONNECT
+* url:请求地址
+* req:请求参数, 为 nil 时不发送请求体
+ */
+func DoJSONWithError[Req, Resp, E any](ctx context.Context, c *Client, method, url string, req Req) (Resp, error) {
+	var out Resp
+
+	response, err := doJSON(ctx, c, method, url, req)
+	if err != nil {
+		// 只有服务端返回了错误响应体时才解析
+		e, ok := err.(errors.Error)
+		if !ok || response.StatusCode == 0 || len(response.ResponseBodyBytes) == 0 {
+			return out, err
+		}
+
+		errBody := &ErrorBody[E]{err: e}
+		if decodeErr := response.DecodeJSON(&errBody.Body); decodeErr != nil {
+			return out, err
+		}
+		return out, errBody
+	}
+
+	if len(response.ResponseBodyBytes) == 0 {
+		return out, nil
+	}
+
+	err = response.DecodeJSON(&out)
+	return out, err
+}
+
+// 序列化请求参数并发起请求
+func doJSON[Req any](ctx context.Context, c *Client, method, url string, req Req) (*HTTPResponse, error) {
+	if c == nil {
+		c = HTTP
+	}
+
+	var input []byte
+	if !isNil(req) {
+		b, err := json.Marshal(req)
+		if err != nil {
+			return &HTTPResponse{}, errors.NewClientError(errors.JsonMarshalErrorCode, errors.JsonMarshalErrorMessage, err)
+		}
+		input = b
+	}
+
+	return c.RequestWithContext(ctx, method, url, input, 0)
+}
+
+// 判断参数是否为 nil, 包括值为 nil 的指针、map、slice 等
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Chan, reflect.Func:
+		return rv.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"io"
+	"net/http"
+	"testing"
+
+	commonErrors "github.com/shzy2012/common/errors"
+)
+
+type jsonUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type jsonError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// 测试 JSON 请求与响应
+func TestDoJSON(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		var in jsonUser
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Errorf("Invalid request body: %v", err)
+		}
+		in.ID = 42
+		json.NewEncoder(w).Encode(in)
+	})
+	defer server.Close()
+
+	out, err := DoJSON[jsonUser, jsonUser](context.Background(), NewClient(), POST, server.URL, jsonUser{Name: "joey"})
+	if err != nil {
+		t.Fatalf("DoJSON failed: %v", err)
+	}
+
+	if out.ID != 42 || out.Name != "joey" {
+		t.Errorf("Unexpected response %+v", out)
+	}
+}
+
+// 测试值为 nil 的指针、map、slice 不发送请求体
+func TestDoJSON_NilRequest(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.NewEncoder(w).Encode(jsonUser{Name: string(body)})
+	})
+	defer server.Close()
+
+	client := NewClient()
+	if out, err := DoJSON[*jsonUser, jsonUser](context.Background(), client, GET, server.URL, nil); err != nil || out.Name != "" {
+		t.Errorf("Expected no body for nil pointer, got '%s' %v", out.Name, err)
+	}
+	if out, _ := DoJSON[map[string]int, jsonUser](context.Background(), client, POST, server.URL, nil); out.Name != "" {
+		t.Errorf("Expected no body for nil map, got '%s'", out.Name)
+	}
+	if out, _ := DoJSON[[]int, jsonUser](context.Background(), client, POST, server.URL, nil); out.Name != "" {
+		t.Errorf("Expected no body for nil slice, got '%s'", out.Name)
+	}
+	if out, _ := DoJSON[[]int, jsonUser](context.Background(), client, POST, server.URL, []int{}); out.Name != "[]" {
+		t.Errorf("Expected empty slice to be sent, got '%s'", out.Name)
+	}
+}
+
+// 测试 JSON 序列化与反序列化错误码
+func TestDoJSON_Errors(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("not json"))
+	})
+	defer server.Close()
+
+	_, err := DoJSON[any, jsonUser](context.Background(), nil, GET, server.URL, nil)
+	if e, ok := err.(commonErrors.Error); !ok || e.ErrorCode() != commonErrors.JsonUnmarshalErrorCode {
+		t.Errorf("Expected JsonUnmarshalError, got %v", err)
+	}
+
+	_, err = DoJSON[chan int, jsonUser](context.Background(), nil, POST, server.URL, make(chan int))
+	if e, ok := err.(commonErrors.Error); !ok || e.ErrorCode() != commonErrors.JsonMarshalErrorCode {
+		t.Errorf("Expected JsonMarshalError, got %v", err)
+	}
+}
+
+// 测试非 2xx 时解析错误响应体
+func TestDoJSONWithError(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"code":"invalid_name","message":"name is required"}`))
+	})
+	defer server.Close()
+
+	_, err := DoJSONWithError[jsonUser, jsonUser, jsonError](context.Background(), NewClient(), POST, server.URL, jsonUser{})
+
+	var e *ErrorBody[jsonError]
+	if !stdErrors.As(err, &e) {
+		t.Fatalf("Expected *ErrorBody, got %T %v", err, err)
+	}
+
+	if e.Body.Code != "invalid_name" || e.HttpStatus() != http.StatusUnprocessableEntity {
+		t.Errorf("Unexpected error body %+v (%d)", e.Body, e.HttpStatus())
+	}
+
+	var serverErr *commonErrors.ServerError
+	if !stdErrors.As(err, &serverErr) {
+		t.Error("Expected ErrorBody to unwrap to ServerError")
+	}
+}
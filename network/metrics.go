@@ -0,0 +1,84 @@
+package network
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/shzy2012/common/errors"
+)
+
+// RequestMetric 单次调用(包括重试)的指标
+type RequestMetric struct {
+	Method     string
+	Host       string
+	StatusCode int           // 未收到响应时为 0
+	ErrorCode  string        // 调用失败时的错误码, 成功时为空
+	Duration   time.Duration // 调用总耗时, 流式请求为收到响应头的耗时
+}
+
+// MetricsHook 每次调用结束后上报指标
+type MetricsHook interface {
+	Observe(m RequestMetric)
+}
+
+// MetricsHookFunc 函数形式的 MetricsHook
+type MetricsHookFunc func(m RequestMetric)
+
+// Observe 实现 MetricsHook
+func (f MetricsHookFunc) Observe(m RequestMetric) {
+	f(m)
+}
+
+// 设置指标钩子, 为 nil 时关闭
+func (c *Client) SetMetrics(hook MetricsHook) {
+	c.metrics = hook
+}
+
+// 设置指标钩子
+func WithMetrics(hook MetricsHook) Option {
+	return func(c *Client) error {
+		c.metrics = hook
+		return nil
+	}
+}
+
+// 调用结束: 执行响应拦截器并上报指标
+func (c *Client) finish(req *http.Request, method string, start time.Time, response *HTTPResponse, err error) error {
+	err = c.interceptors.response(req, response, err)
+
+	if c.metrics != nil {
+		m := RequestMetric{Method: method, Duration: time.Since(start)}
+		if req != nil {
+			m.Host = req.URL.Host
+		}
+		if response != nil {
+			m.StatusCode = response.StatusCode
+		}
+		if err != nil {
+			m.ErrorCode = errorCode(err)
+		}
+		c.metrics.Observe(m)
+	}
+
+	return err
+}
+
+// 错误码, 非 errors.Error 的错误按类型归类
+func errorCode(err error) string {
+	var e errors.Error
+	switch {
+	case stdErrors.As(err, &e):
+		// ServerError 没有错误码, 状态码已包含在 StatusCode 中
+		if code := e.ErrorCode(); code != "" {
+			return code
+		}
+		return "ServerError"
+	case stdErrors.Is(err, context.Canceled):
+		return "Canceled"
+	case stdErrors.Is(err, context.DeadlineExceeded):
+		return "DeadlineExceeded"
+	}
+	return "Unknown"
+}
@@ -0,0 +1,103 @@
+package network
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	commonErrors "github.com/shzy2012/common/errors"
+)
+
+// 测试每次调用结束后上报指标
+func TestClient_Metrics(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	defer server.Close()
+
+	var metrics []RequestMetric
+	client := NewClient(WithMetrics(MetricsHookFunc(func(m RequestMetric) {
+		metrics = append(metrics, m)
+	})))
+
+	client.Request("get", server.URL, nil, 0)
+	client.Request("GET", server.URL+"/fail", nil, 0)
+	client.PostForm2(server.URL, map[string]string{"a": "b"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client.RequestWithContext(ctx, "GET", server.URL, nil, 0)
+
+	if len(metrics) != 4 {
+		t.Fatalf("Expected 4 metrics, got %d", len(metrics))
+	}
+
+	host, _ := url.Parse(server.URL)
+	if m := metrics[0]; m.Method != GET || m.Host != host.Host || m.StatusCode != 200 || m.ErrorCode != "" || m.Duration <= 0 {
+		t.Errorf("Unexpected metric %+v", m)
+	}
+	if m := metrics[1]; m.StatusCode != 404 || m.ErrorCode != "ServerError" {
+		t.Errorf("Unexpected metric %+v", m)
+	}
+	if m := metrics[2]; m.Method != POST || m.StatusCode != 200 {
+		t.Errorf("Unexpected metric %+v", m)
+	}
+	if m := metrics[3]; m.StatusCode != 0 || m.ErrorCode != commonErrors.NetWorkErrorCode {
+		t.Errorf("Unexpected metric %+v", m)
+	}
+}
+
+// 测试导出 Prometheus 文本格式
+func TestPrometheusExporter(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	defer server.Close()
+
+	exporter := NewPrometheusExporter("app", 0.5, 0.1)
+	client := NewClient(WithMetrics(exporter))
+	client.Request("GET", server.URL, nil, 0)
+	client.Request("GET", server.URL, nil, 0)
+	client.Request("GET", server.URL+"/fail", nil, 0)
+
+	exporter.Observe(RequestMetric{Method: "GET", Host: "a\"b", ErrorCode: "Unknown"})
+
+	recorder := httptest.NewRecorder()
+	exporter.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected Content-Type '%s'", recorder.Header().Get("Content-Type"))
+	}
+
+	body, _ := io.ReadAll(recorder.Body)
+	output := string(body)
+	host, _ := url.Parse(server.URL)
+	labels := `method="GET",host="` + host.Host + `"`
+
+	for _, want := range []string{
+		"# TYPE app_http_client_requests_total counter",
+		"app_http_client_requests_total{" + labels + `,status="200"} 2`,
+		"app_http_client_requests_total{" + labels + `,status="503"} 1`,
+		"app_http_client_errors_total{" + labels + `,code="ServerError"} 1`,
+		`app_http_client_errors_total{method="GET",host="a\"b",code="Unknown"} 1`,
+		"# TYPE app_http_client_request_duration_seconds histogram",
+		"app_http_client_request_duration_seconds_bucket{" + labels + `,le="0.1"} 3`,
+		"app_http_client_request_duration_seconds_bucket{" + labels + `,le="+Inf"} 3`,
+		"app_http_client_request_duration_seconds_count{" + labels + "} 3",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain '%s', got:\n%s", want, output)
+		}
+	}
+}
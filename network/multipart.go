@@ -0,0 +1,294 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shzy2012/common/tools"
+)
+
+// FormFile multipart 上传的文件
+type FormFile struct {
+	Name        string    // 表单字段名
+	Filename    string    // 文件名, 为空时使用 *os.File 的文件名
+	ContentType string    // 为空时根据文件扩展名通过 tools.GetContentType 推断
+	Reader      io.Reader // 文件内容, 实现 io.Seeker 时重试会从头重新读取
+}
+
+// Read 实现 io.Reader, 可以直接放入 PostForm 的 form 中
+func (f *FormFile) Read(p []byte) (int, error) {
+	return f.Reader.Read(p)
+}
+
+// MultipartOptions multipart 上传的选项
+type MultipartOptions struct {
+	Retry    int                        // 重试次数, 客户端设置了 RetryPolicy 时以策略为准; 请求体无法重放时不重试
+	Progress func(written, total int64) // 上传进度, total 未知时为 -1, 重试时从 0 开始
+}
+
+// 发起流式 multipart 请求, 请求体边读边发送, 不会整体缓存在内存中
+// 调用方负责关闭 files 中的 Reader
+/* example
+f, _ := os.Open("report.pdf")
+defer f.Close()
+resp, err := client.PostMultipart(ctx, url, map[string]string{"title": "report"},
+	[]*network.FormFile{{Name: "file", Reader: f}},
+	&network.MultipartOptions{Retry: 2, Progress: func(written, total int64) {}})
+*/
+func (c *Client) PostMultipart(ctx context.Context, url string, fields map[string]string, files []*FormFile, opts *MultipartOptions) (*HTTPResponse, error) {
+	if opts == nil {
+		opts = &MultipartOptions{}
+	}
+
+	body, err := newMultipartBody(fields, files, opts.Progress)
+	if err != nil {
+		return &HTTPResponse{}, err
+	}
+
+	return c.do(ctx, &request{
+		method:        POST,
+		url:           url,
+		header:        http.Header{ContentType: {body.contentType()}},
+		getBody:       body.open,
+		contentLength: body.total,
+		replayable:    body.replayable(),
+		retry:         opts.Retry,
+	})
+}
+
+// 流式生成的 multipart 请求体
+type multipartBody struct {
+	boundary string
+	fields   []multipartField
+	files    []*multipartFile
+	total    int64 // 请求体长度, 未知时为 -1
+	progress func(written, total int64)
+
+	mu   sync.Mutex
+	pipe *io.PipeReader // 上一次打开的请求体
+	done chan struct{}  // 上一次的写入协程结束时关闭
+}
+
+type multipartField struct {
+	name, value string
+}
+
+type multipartFile struct {
+	*FormFile
+	start int64 // 可 Seek 时的起始位置
+}
+
+func newMultipartBody(fields map[string]string, files []*FormFile, progress func(written, total int64)) (*multipartBody, error) {
+	b := &multipartBody{boundary: multipart.NewWriter(nil).Boundary(), progress: progress}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.fields = append(b.fields, multipartField{name, fields[name]})
+	}
+
+	for _, file := range files {
+		if file == nil || file.Reader == nil {
+			continue
+		}
+
+		f := *file
+		if f.Filename == "" {
+			if x, ok := f.Reader.(*os.File); ok {
+				f.Filename = filepath.Base(x.Name())
+			} else {
+				f.Filename = f.Name
+			}
+		}
+		if f.ContentType == "" {
+			f.ContentType = tools.GetContentType(filepath.Ext(f.Filename))
+		}
+
+		mf := &multipartFile{FormFile: &f, start: -1}
+		if seeker, ok := f.Reader.(io.Seeker); ok {
+			if start, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+				mf.start = start
+			}
+		}
+		b.files = append(b.files, mf)
+	}
+
+	b.total = b.size()
+	return b, nil
+}
+
+func (b *multipartBody) contentType() string {
+	return "multipart/form-data; boundary=" + b.boundary
+}
+
+// 所有文件都可以 Seek 时请求体可以重放
+func (b *multipartBody) replayable() bool {
+	for _, f := range b.files {
+		if f.start < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// 计算请求体长度, 存在长度未知的文件时返回 -1
+func (b *multipartBody) size() int64 {
+	counter := &countWriter{w: io.Discard}
+	w := multipart.NewWriter(counter)
+	w.SetBoundary(b.boundary)
+
+	for _, field := range b.fields {
+		fw, _ := w.CreateFormField(field.name)
+		io.WriteString(fw, field.value)
+	}
+
+	var total int64
+	for _, f := range b.files {
+		size := readerSize(f.Reader, f.start)
+		if size < 0 {
+			return -1
+		}
+		total += size
+		w.CreatePart(f.header())
+	}
+	w.Close()
+
+	return counter.n + total
+}
+
+// 剩余可读取的长度, 未知时返回 -1
+func readerSize(r io.Reader, start int64) int64 {
+	switch x := r.(type) {
+	case interface{ Len() int }:
+		return int64(x.Len())
+	case *os.File:
+		if info, err := x.Stat(); err == nil && info.Mode().IsRegular() && start >= 0 {
+			return info.Size() - start
+		}
+	}
+	return -1
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func (f *multipartFile) header() textproto.MIMEHeader {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(f.Name), quoteEscaper.Replace(f.Filename)))
+	h.Set("Content-Type", f.ContentType)
+	return h
+}
+
+// 打开请求体, 重试时关闭上一次的请求体并等待写入结束, 再将文件重置到起始位置
+func (b *multipartBody) open() (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pipe != nil {
+		b.pipe.CloseWithError(fmt.Errorf("multipart body reopened"))
+		<-b.done
+		for _, f := range b.files {
+			if f.start < 0 {
+				return nil, fmt.Errorf("multipart file %q cannot be rewound", f.Filename)
+			}
+			if _, err := f.Reader.(io.Seeker).Seek(f.start, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	b.pipe, b.done = pr, done
+	go func() {
+		defer close(done)
+		pw.CloseWithError(b.write(pw))
+	}()
+
+	if b.progress == nil {
+		return pr, nil
+	}
+	return &progressReader{ReadCloser: pr, total: b.total, progress: b.progress}, nil
+}
+
+func (b *multipartBody) write(dst io.Writer) error {
+	w := multipart.NewWriter(dst)
+	w.SetBoundary(b.boundary)
+
+	for _, field := range b.fields {
+		if err := w.WriteField(field.name, field.value); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range b.files {
+		fw, err := w.CreatePart(f.header())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(fw, f.Reader); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// 读取时回调上传进度
+type progressReader struct {
+	io.ReadCloser
+	written  int64
+	total    int64
+	progress func(written, total int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.written += int64(n)
+		r.progress(r.written, r.total)
+	}
+	return n, err
+}
+
+// 将 PostForm 的 form 转换为字段和文件: *os.File 和 *FormFile 作为文件, 其他作为普通字段
+func formParts(form map[string]io.Reader) (map[string]string, []*FormFile, error) {
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := map[string]string{}
+	var files []*FormFile
+	for _, name := range names {
+		switch r := form[name].(type) {
+		case *FormFile:
+			f := *r
+			if f.Name == "" {
+				f.Name = name
+			}
+			files = append(files, &f)
+		case *os.File:
+			files = append(files, &FormFile{Name: name, Reader: r})
+		case nil:
+		default:
+			value, err := io.ReadAll(r)
+			if err != nil {
+				return nil, nil, err
+			}
+			fields[name] = string(value)
+		}
+	}
+	return fields, files, nil
+}
@@ -0,0 +1,158 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// 测试流式 multipart 上传和上传进度
+func TestClient_PostMultipart(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength <= 0 {
+			t.Errorf("Expected known Content-Length, got %d", r.ContentLength)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("ParseMultipartForm failed: %v", err)
+		}
+		if r.FormValue("title") != "report" {
+			t.Errorf("Unexpected field '%s'", r.FormValue("title"))
+		}
+
+		file, header, err := r.FormFile("image")
+		if err != nil {
+			t.Fatalf("FormFile failed: %v", err)
+		}
+		content, _ := io.ReadAll(file)
+		if header.Filename != "a.png" || header.Header.Get("Content-Type") != "image/png" || string(content) != "png-data" {
+			t.Errorf("Unexpected file %s %s '%s'", header.Filename, header.Header.Get("Content-Type"), content)
+		}
+		w.Write([]byte("ok"))
+	})
+	defer server.Close()
+
+	var written, total int64
+	response, err := NewClient().PostMultipart(context.Background(), server.URL,
+		map[string]string{"title": "report"},
+		[]*FormFile{{Name: "image", Filename: "a.png", Reader: strings.NewReader("png-data")}},
+		&MultipartOptions{Progress: func(w, t int64) { written, total = w, t }})
+	if err != nil || response.ToString() != "ok" {
+		t.Fatalf("PostMultipart failed: %v", err)
+	}
+
+	if written == 0 || written != total {
+		t.Errorf("Expected progress to reach total, got %d/%d", written, total)
+	}
+}
+
+// 测试重试时重新读取可 Seek 的文件
+func TestClient_PostMultipart_Retry(t *testing.T) {
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("FormFile failed: %v", err)
+		}
+		content, _ := io.ReadAll(file)
+		if string(content) != "file-content" {
+			t.Errorf("Unexpected content on attempt %d: '%s'", calls+1, content)
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "data.txt")
+	os.WriteFile(path, []byte("file-content"), 0600)
+	f, _ := os.Open(path)
+	defer f.Close()
+
+	client := NewClient(WithRetryPolicy(&ExponentialBackoff{MaxRetries: 1}))
+	response, err := client.PostMultipart(context.Background(), server.URL, nil, []*FormFile{{Name: "file", Reader: f}}, nil)
+	if err != nil || response.ToString() != "ok" || atomic.LoadInt32(&calls) != 2 {
+		t.Fatalf("Expected retry to succeed, got %v after %d calls", err, calls)
+	}
+
+	// 无法重放的请求体不重试
+	atomic.StoreInt32(&calls, 0)
+	_, err = client.PostMultipart(context.Background(), server.URL, nil,
+		[]*FormFile{{Name: "file", Reader: io.MultiReader(strings.NewReader("file-content"))}}, nil)
+	if err == nil || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected non-seekable body not to be retried, got %v after %d calls", err, calls)
+	}
+}
+
+// 测试上传中途断开连接后重试, 上一次的写入结束后才重置文件
+func TestClient_PostMultipart_RetryAfterDisconnect(t *testing.T) {
+	payload := bytes.Repeat([]byte("0123456789abcdef"), 1<<18)
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			io.CopyN(io.Discard, r.Body, 1024)
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		r.ParseMultipartForm(1 << 20)
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("FormFile failed: %v", err)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		if !bytes.Equal(content, payload) {
+			t.Errorf("Corrupted file on retry, got %d bytes", len(content))
+		}
+		w.Write([]byte("ok"))
+	})
+	defer server.Close()
+
+	client := NewClient(WithRetryPolicy(&ExponentialBackoff{MaxRetries: 1}))
+	response, err := client.PostMultipart(context.Background(), server.URL, nil, []*FormFile{{Name: "file", Reader: bytes.NewReader(payload)}}, nil)
+	if err != nil || response.ToString() != "ok" {
+		t.Fatalf("Expected retry to succeed, got %v after %d calls", err, calls)
+	}
+}
+
+// 测试 PostForm 的文件名、类型推断以及关闭文件
+func TestClient_PostForm_File(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		_, header, err := r.FormFile("doc")
+		if err != nil {
+			t.Fatalf("FormFile failed: %v", err)
+		}
+		w.Write([]byte(header.Filename + "|" + header.Header.Get("Content-Type") + "|" + r.FormValue("k") + "|" + r.FormValue("raw")))
+	})
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	os.WriteFile(path, []byte("%PDF"), 0600)
+	f, _ := os.Open(path)
+
+	response, err := NewClient().PostForm(server.URL, map[string]io.Reader{
+		"doc": f,
+		"k":   strings.NewReader("v"),
+		"raw": &FormFile{Reader: bytes.NewReader([]byte("x")), Filename: "x.bin"},
+	})
+	if err != nil {
+		t.Fatalf("PostForm failed: %v", err)
+	}
+	if response.ToString() != "report.pdf|application/pdf|v|" {
+		t.Errorf("Unexpected form '%s'", response.ToString())
+	}
+
+	if _, err := f.Stat(); err == nil {
+		t.Error("Expected PostForm to close the file")
+	}
+}
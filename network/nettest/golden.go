@@ -0,0 +1,98 @@
+package nettest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+)
+
+// Interaction 录制的一次请求和响应
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest 录制的请求
+type RecordedRequest struct {
+	Method string              `json:"method"`
+	URL    string              `json:"url"`
+	Header map[string][]string `json:"header,omitempty"`
+	Body   Body                `json:"body,omitempty"`
+}
+
+// RecordedResponse 录制的响应
+type RecordedResponse struct {
+	StatusCode int                 `json:"status_code"`
+	Header     map[string][]string `json:"header,omitempty"`
+	Body       Body                `json:"body,omitempty"`
+}
+
+// Body 请求体或响应体, 文本原样保存, 二进制内容保存为 base64
+type Body []byte
+
+// MarshalJSON 实现 json.Marshaler
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = Body(s)
+		return nil
+	}
+
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	if err != nil {
+		return err
+	}
+	*b = raw
+	return nil
+}
+
+// 黄金文件的格式
+type golden struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadGolden 读取黄金文件
+func LoadGolden(path string) ([]Interaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var g golden
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, err
+	}
+	return g.Interactions, nil
+}
+
+// SaveGolden 写入黄金文件, 目录不存在时自动创建
+func SaveGolden(path string, interactions []Interaction) error {
+	if interactions == nil {
+		interactions = []Interaction{}
+	}
+	data, err := json.MarshalIndent(golden{Interactions: interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
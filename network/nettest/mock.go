@@ -0,0 +1,318 @@
+package nettest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Matcher 请求匹配规则, body 为已读取的请求体
+type Matcher func(req *http.Request, body []byte) bool
+
+// Method 匹配请求方法, 不区分大小写
+func Method(method string) Matcher {
+	return func(req *http.Request, body []byte) bool {
+		return strings.EqualFold(req.Method, method)
+	}
+}
+
+// URL 匹配完整的请求地址
+func URL(url string) Matcher {
+	return func(req *http.Request, body []byte) bool {
+		return req.URL.String() == url
+	}
+}
+
+// Path 匹配请求路径
+func Path(path string) Matcher {
+	return func(req *http.Request, body []byte) bool {
+		return req.URL.Path == path
+	}
+}
+
+// Query 匹配查询参数
+func Query(key, value string) Matcher {
+	return func(req *http.Request, body []byte) bool {
+		return req.URL.Query().Get(key) == value
+	}
+}
+
+// Header 匹配请求头
+func Header(key, value string) Matcher {
+	return func(req *http.Request, body []byte) bool {
+		return req.Header.Get(key) == value
+	}
+}
+
+// BodyEquals 匹配完整的请求体
+func BodyEquals(s string) Matcher {
+	return func(req *http.Request, body []byte) bool {
+		return string(body) == s
+	}
+}
+
+// BodyContains 匹配包含 s 的请求体
+func BodyContains(s string) Matcher {
+	return func(req *http.Request, body []byte) bool {
+		return bytes.Contains(body, []byte(s))
+	}
+}
+
+// JSONBody 匹配 JSON 请求体, 忽略字段顺序和空白
+func JSONBody(v interface{}) Matcher {
+	want, err := normalizeJSON(v)
+	return func(req *http.Request, body []byte) bool {
+		if err != nil {
+			return false
+		}
+		var got interface{}
+		if json.Unmarshal(body, &got) != nil {
+			return false
+		}
+		return reflect.DeepEqual(got, want)
+	}
+}
+
+func normalizeJSON(v interface{}) (interface{}, error) {
+	data, ok := v.([]byte)
+	if s, isString := v.(string); isString {
+		data, ok = []byte(s), true
+	}
+	if !ok {
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+
+	var out interface{}
+	err := json.Unmarshal(data, &out)
+	return out, err
+}
+
+// Call 一次请求记录
+type Call struct {
+	Request *http.Request
+	Body    []byte
+}
+
+// Mock 可编程的 http.RoundTripper, 按注册顺序匹配 Stub 并返回预设的响应
+/* example
+mock := nettest.NewMock()
+mock.On(nettest.Method("GET"), nettest.Path("/users/1")).ReplyJSON(200, user)
+mock.On(nettest.Method("POST")).Reply(503, "busy").Once()
+client := network.NewClient()
+client.SetTransport(mock)
+...
+mock.AssertExpectations(t)
+*/
+type Mock struct {
+	mu        sync.Mutex
+	stubs     []*Stub
+	calls     []Call
+	unmatched []Call
+}
+
+// NewMock 创建 Mock
+func NewMock() *Mock {
+	return &Mock{}
+}
+
+// On 注册匹配所有 matchers 的 Stub, 没有 matchers 时匹配所有请求
+func (m *Mock) On(matchers ...Matcher) *Stub {
+	s := &Stub{matchers: matchers, status: http.StatusOK, header: http.Header{}}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stubs = append(m.stubs, s)
+	return s
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (m *Mock) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	call := Call{Request: req, Body: body}
+
+	m.mu.Lock()
+	m.calls = append(m.calls, call)
+	var stub *Stub
+	for _, s := range m.stubs {
+		if s.exhausted() || !s.match(req, body) {
+			continue
+		}
+		stub = s
+		stub.calls++
+		break
+	}
+	if stub == nil {
+		m.unmatched = append(m.unmatched, call)
+	}
+	m.mu.Unlock()
+
+	if stub == nil {
+		return nil, fmt.Errorf("nettest: no stub for %s %s", req.Method, req.URL)
+	}
+	return stub.respond(req)
+}
+
+// Calls 所有请求记录
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call{}, m.calls...)
+}
+
+// AssertCalled 断言匹配 matchers 的请求次数为 n
+func (m *Mock) AssertCalled(t testing.TB, n int, matchers ...Matcher) bool {
+	t.Helper()
+
+	count := 0
+	for _, call := range m.Calls() {
+		if matchAll(matchers, call.Request, call.Body) {
+			count++
+		}
+	}
+	if count != n {
+		t.Errorf("nettest: expected %d matching calls, got %d", n, count)
+		return false
+	}
+	return true
+}
+
+// AssertExpectations 断言每个 Stub 都按预期被调用, 并且没有未匹配的请求
+// 设置了 Times 的 Stub 必须恰好调用 n 次, 其余 Stub 至少调用一次
+func (m *Mock) AssertExpectations(t testing.TB) bool {
+	t.Helper()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ok := true
+	for i, s := range m.stubs {
+		switch {
+		case s.times > 0 && s.calls != s.times:
+			t.Errorf("nettest: stub #%d expected %d calls, got %d", i, s.times, s.calls)
+			ok = false
+		case s.times == 0 && s.calls == 0:
+			t.Errorf("nettest: stub #%d was never called", i)
+			ok = false
+		}
+	}
+	for _, call := range m.unmatched {
+		t.Errorf("nettest: unexpected request %s %s", call.Request.Method, call.Request.URL)
+		ok = false
+	}
+	return ok
+}
+
+// Stub 预设的响应
+type Stub struct {
+	matchers []Matcher
+	status   int
+	header   http.Header
+	body     []byte
+	err      error
+	fn       func(req *http.Request) (*http.Response, error)
+	delay    time.Duration
+	times    int // 最多匹配的次数, 0 表示不限
+	calls    int
+}
+
+// Reply 返回状态码和响应体
+func (s *Stub) Reply(status int, body string) *Stub {
+	s.status, s.body = status, []byte(body)
+	return s
+}
+
+// ReplyJSON 返回 JSON 响应体
+func (s *Stub) ReplyJSON(status int, v interface{}) *Stub {
+	body, err := json.Marshal(v)
+	if err != nil {
+		s.err = err
+		return s
+	}
+	s.status, s.body = status, body
+	s.header.Set("Content-Type", "application/json")
+	return s
+}
+
+// ReplyHeader 设置响应头
+func (s *Stub) ReplyHeader(key, value string) *Stub {
+	s.header.Add(key, value)
+	return s
+}
+
+// ReplyError 返回网络错误
+func (s *Stub) ReplyError(err error) *Stub {
+	s.err = err
+	return s
+}
+
+// ReplyFunc 由 fn 生成响应
+func (s *Stub) ReplyFunc(fn func(req *http.Request) (*http.Response, error)) *Stub {
+	s.fn = fn
+	return s
+}
+
+// Delay 延迟返回响应, 请求的 ctx 取消时提前返回错误
+func (s *Stub) Delay(d time.Duration) *Stub {
+	s.delay = d
+	return s
+}
+
+// Times 最多匹配 n 次, 之后的请求由后续的 Stub 匹配
+func (s *Stub) Times(n int) *Stub {
+	s.times = n
+	return s
+}
+
+// Once 只匹配一次
+func (s *Stub) Once() *Stub {
+	return s.Times(1)
+}
+
+func (s *Stub) exhausted() bool {
+	return s.times > 0 && s.calls >= s.times
+}
+
+func (s *Stub) match(req *http.Request, body []byte) bool {
+	return matchAll(s.matchers, req, body)
+}
+
+func (s *Stub) respond(req *http.Request) (*http.Response, error) {
+	if s.delay > 0 {
+		timer := time.NewTimer(s.delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	if s.fn != nil {
+		return s.fn(req)
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return newResponse(req, s.status, s.header, s.body), nil
+}
+
+func matchAll(matchers []Matcher, req *http.Request, body []byte) bool {
+	for _, match := range matchers {
+		if !match(req, body) {
+			return false
+		}
+	}
+	return true
+}
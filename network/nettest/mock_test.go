@@ -0,0 +1,84 @@
+package nettest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shzy2012/common/network"
+)
+
+// 测试匹配规则、顺序响应和调用断言
+func TestMock(t *testing.T) {
+	mock := NewMock()
+	mock.On(Method("POST"), Path("/users"), JSONBody(`{"name":"joey","age":3}`)).Reply(503, "busy").Once()
+	mock.On(Method("POST"), Path("/users")).ReplyJSON(201, map[string]int{"id": 1})
+	mock.On(Method("GET"), Query("id", "1"), Header("X-Trace", "abc")).Reply(200, "found")
+
+	client := network.NewClient(network.WithHeader("X-Trace", "abc"), network.WithRetryPolicy(&network.ExponentialBackoff{MaxRetries: 1}))
+	client.SetTransport(mock)
+
+	response, err := client.Request("POST", "http://api.test/users", []byte(`{"age":3, "name":"joey"}`), 0)
+	if err != nil || response.StatusCode != 201 || response.ToString() != `{"id":1}` {
+		t.Fatalf("Expected retry to hit second stub, got %v %d '%s'", err, response.StatusCode, response.ToString())
+	}
+
+	response, _ = client.Request("GET", "http://api.test/users?id=1", nil, 0)
+	if response.ToString() != "found" {
+		t.Errorf("Unexpected response '%s'", response.ToString())
+	}
+
+	mock.AssertCalled(t, 2, Method("POST"), BodyContains("joey"))
+	mock.AssertCalled(t, 1, URL("http://api.test/users?id=1"))
+	mock.AssertExpectations(t)
+
+	// 未匹配的请求, 网络错误会按策略重试一次
+	if _, err := client.Request("DELETE", "http://api.test/users", nil, 0); err == nil {
+		t.Error("Expected error for unmatched request")
+	}
+	if len(mock.Calls()) != 5 {
+		t.Errorf("Expected 5 calls, got %d", len(mock.Calls()))
+	}
+
+	tb := &fakeTB{TB: t}
+	if mock.AssertExpectations(tb) || tb.errors != 2 {
+		t.Errorf("Expected AssertExpectations to report 2 unmatched requests, got %d", tb.errors)
+	}
+}
+
+// 记录断言失败而不使测试失败
+type fakeTB struct {
+	testing.TB
+	errors int
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(format string, args ...interface{}) {
+	tb.errors++
+}
+
+// 测试错误和延迟
+func TestMock_ErrorAndDelay(t *testing.T) {
+	mock := NewMock()
+	mock.On(Path("/err")).ReplyError(errors.New("connection reset"))
+	mock.On(Path("/slow")).Reply(200, "slow").Delay(time.Second)
+
+	client := network.NewClient()
+	client.SetTransport(mock)
+
+	if _, err := client.Request("GET", "http://api.test/err", nil, 0); err == nil {
+		t.Error("Expected network error")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.RequestWithContext(ctx, "GET", "http://api.test/slow", nil, 0); err == nil {
+		t.Error("Expected timeout error")
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("Expected delay to be cancelled by ctx")
+	}
+}
@@ -0,0 +1,165 @@
+package nettest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+)
+
+// Redacted 脱敏后的请求头的值
+const Redacted = "REDACTED"
+
+// DefaultRedactHeaders 默认脱敏的请求头和响应头
+var DefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// Recorder 录制请求和响应的 http.RoundTripper
+type Recorder struct {
+	path      string
+	transport http.RoundTripper
+	redact    map[string]bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder 通过 transport 发起请求并录制, 为 nil 时使用 http.DefaultTransport
+// redactHeaders 追加到 DefaultRedactHeaders 之后, 录制时这些头的值会被替换为 Redacted
+/* example
+recorder := nettest.NewRecorder("testdata/users.json", nil)
+client := network.NewClient()
+client.SetTransport(recorder)
+// ... 发起请求
+recorder.Save()
+*/
+func NewRecorder(path string, transport http.RoundTripper, redactHeaders ...string) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	redact := map[string]bool{}
+	for _, key := range append(append([]string{}, DefaultRedactHeaders...), redactHeaders...) {
+		redact[http.CanonicalHeaderKey(key)] = true
+	}
+	return &Recorder{path: path, transport: transport, redact: redact}
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 不修改调用方的请求
+	if reqBody != nil {
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: r.redactHeader(req.Header),
+			Body:   reqBody,
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     r.redactHeader(resp.Header),
+			Body:       respBody,
+		},
+	})
+	return resp, nil
+}
+
+// Interactions 已录制的请求和响应
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction{}, r.interactions...)
+}
+
+// Save 将录制的请求和响应写入黄金文件
+func (r *Recorder) Save() error {
+	return SaveGolden(r.path, r.Interactions())
+}
+
+// 复制请求头并替换敏感的值
+func (r *Recorder) redactHeader(header http.Header) map[string][]string {
+	if len(header) == 0 {
+		return nil
+	}
+
+	h := make(map[string][]string, len(header))
+	for key, values := range header {
+		if r.redact[http.CanonicalHeaderKey(key)] {
+			values = []string{Redacted}
+		}
+		h[key] = append([]string{}, values...)
+	}
+	return h
+}
+
+// 读取并关闭请求体
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
+
+// RecordEnv 设置该环境变量后 Golden 重新录制黄金文件
+const RecordEnv = "NETTEST_RECORD"
+
+// Golden 返回录制或回放的 http.RoundTripper:
+// 设置了环境变量 NETTEST_RECORD 或黄金文件不存在时通过 transport 录制, 测试结束时保存;
+// 否则从黄金文件回放
+/* example
+func TestUsers(t *testing.T) {
+	client := network.NewClient()
+	client.SetTransport(nettest.Golden(t, "testdata/users.json", nil))
+	...
+}
+*/
+func Golden(t testing.TB, path string, transport http.RoundTripper, redactHeaders ...string) http.RoundTripper {
+	t.Helper()
+
+	_, err := os.Stat(path)
+	if os.Getenv(RecordEnv) == "" && err == nil {
+		replayer, err := NewReplayer(path)
+		if err != nil {
+			t.Fatalf("nettest: load golden file %s: %v", path, err)
+		}
+		return replayer
+	}
+
+	recorder := NewRecorder(path, transport, redactHeaders...)
+	t.Cleanup(func() {
+		if t.Failed() {
+			return
+		}
+		if err := recorder.Save(); err != nil {
+			t.Errorf("nettest: save golden file %s: %v", path, err)
+		}
+	})
+	return recorder
+}
@@ -0,0 +1,128 @@
+package nettest
+
+import (
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shzy2012/common/network"
+)
+
+// 测试录制、脱敏和回放
+func TestRecorder_Replay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make([]byte, r.ContentLength)
+		r.Body.Read(body)
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(body)))
+	}))
+
+	path := filepath.Join(t.TempDir(), "testdata", "golden.json")
+	recorder := NewRecorder(path, nil, "X-Token")
+
+	client := network.NewClient(network.WithBasicAuth("user", "pass"), network.WithHeader("X-Token", "t0ken"))
+	client.SetTransport(recorder)
+
+	client.Request("GET", server.URL+"/a", nil, 0)
+	client.Request("POST", server.URL+"/b", []byte("first"), 0)
+	client.Request("POST", server.URL+"/b", []byte("second"), 0)
+	client.Request("GET", server.URL+"/bin", []byte{0xff, 0xfe}, 0)
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	server.Close()
+
+	data, _ := os.ReadFile(path)
+	for _, secret := range []string{"t0ken", "session=secret", "dXNlcjpwYXNz"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Expected %q to be redacted", secret)
+		}
+	}
+
+	// 服务已关闭, 从黄金文件回放
+	replayer, err := NewReplayer(path)
+	if err != nil {
+		t.Fatalf("NewReplayer failed: %v", err)
+	}
+	client.SetTransport(replayer)
+
+	response, _ := client.Request("POST", server.URL+"/b", []byte("second"), 0)
+	if response.ToString() != "POST /b second" {
+		t.Errorf("Unexpected replay '%s'", response.ToString())
+	}
+	response, _ = client.Request("GET", server.URL+"/bin", []byte{0xff, 0xfe}, 0)
+	if response.ToString() != "GET /bin \xff\xfe" {
+		t.Errorf("Unexpected binary replay %q", response.ToString())
+	}
+	if replayer.Remaining() != 2 {
+		t.Errorf("Expected 2 remaining interactions, got %d", replayer.Remaining())
+	}
+
+	// 每条录制只回放一次
+	if _, err := client.Request("POST", server.URL+"/b", []byte("second"), 0); err == nil {
+		t.Error("Expected error for exhausted interaction")
+	}
+}
+
+// 测试压缩的响应以解压后的内容录制
+func TestRecorder_Gzip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Write([]byte("plain"))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gw := gzip.NewWriter(w)
+		gw.Write([]byte("readable body"))
+		gw.Close()
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "gzip.json")
+	recorder := NewRecorder(path, nil)
+	client := network.NewClient()
+	client.SetTransport(recorder)
+
+	response, err := client.Request("GET", server.URL, nil, 0)
+	if err != nil || response.ToString() != "readable body" {
+		t.Fatalf("Unexpected response '%s' (%v)", response.ToString(), err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "readable body") {
+		t.Errorf("Expected decoded body in golden file, got %s", data)
+	}
+}
+
+// 测试 Golden 在文件不存在时录制, 存在时回放
+func TestGolden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("live"))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "golden.json")
+	t.Setenv(RecordEnv, "")
+
+	t.Run("record", func(t *testing.T) {
+		client := network.NewClient()
+		client.SetTransport(Golden(t, path, nil))
+		client.Request("GET", server.URL, nil, 0)
+	})
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected golden file to be saved: %v", err)
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		transport := Golden(t, path, nil)
+		if _, ok := transport.(*Replayer); !ok {
+			t.Fatalf("Expected replayer, got %T", transport)
+		}
+	})
+}
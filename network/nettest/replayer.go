@@ -0,0 +1,96 @@
+package nettest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Replayer 从黄金文件回放响应的 http.RoundTripper
+// 请求按方法、地址和请求体匹配录制, 每条录制只回放一次, 相同的请求按录制的顺序依次返回
+type Replayer struct {
+	// Match 自定义匹配规则, 为 nil 时比较方法、地址和请求体
+	Match func(req *http.Request, body []byte, recorded RecordedRequest) bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer 读取黄金文件
+func NewReplayer(path string) (*Replayer, error) {
+	interactions, err := LoadGolden(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayerFrom(interactions), nil
+}
+
+// NewReplayerFrom 使用已有的录制
+func NewReplayerFrom(interactions []Interaction) *Replayer {
+	return &Replayer{interactions: interactions, used: make([]bool, len(interactions))}
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	match := r.Match
+	if match == nil {
+		match = defaultMatch
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		if r.used[i] || !match(req, body, interaction.Request) {
+			continue
+		}
+		r.used[i] = true
+		return newResponse(req, interaction.Response.StatusCode, interaction.Response.Header, interaction.Response.Body), nil
+	}
+	return nil, fmt.Errorf("nettest: no recorded interaction for %s %s", req.Method, req.URL)
+}
+
+// Remaining 尚未回放的录制数量
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for _, used := range r.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+func defaultMatch(req *http.Request, body []byte, recorded RecordedRequest) bool {
+	return req.Method == recorded.Method && req.URL.String() == recorded.URL && bytes.Equal(body, recorded.Body)
+}
+
+// 构造响应
+func newResponse(req *http.Request, status int, header map[string][]string, body []byte) *http.Response {
+	h := make(http.Header, len(header))
+	for key, values := range header {
+		h[key] = append([]string{}, values...)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,113 @@
+package network
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testLogger struct {
+	lines []string
+}
+
+func (l *testLogger) Debugf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+// 测试配置项
+func TestNewClient_Options(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		fmt.Fprintf(w, "%s %s %s %s %s", r.URL.Path, r.Header.Get("User-Agent"), r.Header.Get("X-Tenant"), username, password)
+	})
+	defer server.Close()
+
+	logger := &testLogger{}
+	jar, _ := cookiejar.New(nil)
+	client := NewClient(
+		WithBaseURL(server.URL+"/api/"),
+		WithTimeout(3*time.Second),
+		WithConnectionPool(20, 20, 5, time.Minute),
+		WithUserAgent("my-agent"),
+		WithHeaders(map[string]string{"X-Tenant": "t1"}),
+		WithBasicAuth("user", "pass"),
+		WithCookieJar(jar),
+		WithLogger(logger),
+		WithDebug(true),
+	)
+
+	if client.Err() != nil {
+		t.Fatalf("Unexpected option error: %v", client.Err())
+	}
+
+	if client.HttpClient.Timeout != 3*time.Second || client.HttpClient.Jar != jar {
+		t.Error("Timeout or cookie jar not applied")
+	}
+
+	if client.transport.MaxIdleConns != 20 || client.transport.MaxIdleConnsPerHost != 5 {
+		t.Error("Connection pool not applied")
+	}
+
+	response, err := client.Request("GET", "/users", nil, 0)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if want := "/api/users my-agent t1 user pass"; response.ToString() != want {
+		t.Errorf("Expected '%s', got '%s'", want, response.ToString())
+	}
+
+	if len(logger.lines) == 0 {
+		t.Error("Expected debug logs to be written to custom logger")
+	}
+
+	// 完整地址不受基础地址影响
+	response, _ = client.Request("GET", server.URL+"/other", nil, 0)
+	if !strings.HasPrefix(response.ToString(), "/other ") {
+		t.Errorf("Expected absolute url to be used as is, got '%s'", response.ToString())
+	}
+}
+
+// 测试非法配置项
+func TestNewClient_InvalidOptions(t *testing.T) {
+	for _, opt := range []Option{WithBaseURL("://bad"), WithBaseURL("example.com"), WithProxy("")} {
+		if NewClient(opt).Err() == nil {
+			t.Error("Expected option error")
+		}
+	}
+}
+
+// 测试 Clone 生成独立副本
+func TestClient_Clone(t *testing.T) {
+	client := NewClient(WithHeader("X-A", "a"))
+	client.SetCookie(&http.Cookie{Name: "session", Value: "1"})
+
+	clone := client.Clone()
+	clone.Header["X-B"] = "b"
+	clone.SetCookie(&http.Cookie{Name: "user", Value: "2"})
+	clone.SetHTTPTimeout(time.Second)
+	clone.SetMaxIdleConns(1)
+
+	if _, ok := client.Header["X-B"]; ok {
+		t.Error("Clone should have its own header map")
+	}
+
+	if len(client.Cookies) != 1 || len(clone.Cookies) != 2 {
+		t.Errorf("Clone should have its own cookies, got %d and %d", len(client.Cookies), len(clone.Cookies))
+	}
+
+	if client.HttpClient.Timeout == time.Second {
+		t.Error("Clone should have its own http.Client")
+	}
+
+	if client.transport.MaxIdleConns == 1 || clone.HttpClient.Transport != http.RoundTripper(clone.transport) {
+		t.Error("Clone should have its own connection pool")
+	}
+
+	if clone.HttpClient.Jar == client.HttpClient.Jar {
+		t.Error("Clone should have its own cookie jar")
+	}
+}
@@ -0,0 +1,304 @@
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// LatencyStats 耗时统计
+type LatencyStats struct {
+	Count int64
+	Total time.Duration
+	Max   time.Duration
+}
+
+// Avg 平均耗时
+func (s LatencyStats) Avg() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+func (s *LatencyStats) observe(d time.Duration) {
+	s.Count++
+	s.Total += d
+	if d > s.Max {
+		s.Max = d
+	}
+}
+
+// HostPoolStats 单个 host 的连接池统计
+type HostPoolStats struct {
+	Requests     int64 // 发送的请求数
+	InFlight     int64 // 进行中的请求数(响应体关闭前)
+	ConnsCreated int64 // 新建的连接数
+	ConnsReused  int64 // 复用的连接数
+	IdleReused   int64 // 复用的连接中来自空闲连接池的次数
+}
+
+// PoolStats 连接池实时统计
+type PoolStats struct {
+	// 连接池配置
+	MaxIdleConns        int
+	MaxConnsPerHost     int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+
+	// 连接状态, 仅统计客户端内置 Transport 建立的连接
+	OpenConns   int64 // 当前打开的连接数
+	ActiveConns int64 // 正在使用的连接数
+	IdleConns   int64 // 空闲的连接数
+
+	Requests     int64
+	InFlight     int64
+	ConnsCreated int64
+	ConnsReused  int64
+	IdleReused   int64
+
+	// 各阶段耗时
+	DNS     LatencyStats
+	Connect LatencyStats
+	TLS     LatencyStats
+	TTFB    LatencyStats // 从发送请求到收到首字节
+
+	Hosts map[string]HostPoolStats
+}
+
+// 通过 httptrace 收集连接池指标
+type poolMetrics struct {
+	mu      sync.Mutex
+	dial    func(ctx context.Context, network, addr string) (net.Conn, error)
+	open    int64
+	active  int64
+	dns     LatencyStats
+	connect LatencyStats
+	tls     LatencyStats
+	ttfb    LatencyStats
+	hosts   map[string]*HostPoolStats
+}
+
+func newPoolMetrics(dial func(ctx context.Context, network, addr string) (net.Conn, error)) *poolMetrics {
+	return &poolMetrics{dial: dial, hosts: map[string]*HostPoolStats{}}
+}
+
+// 默认的拨号方式, 与 http.DefaultTransport 一致
+func defaultDialContext() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	return dialer.DialContext
+}
+
+// 拨号并统计打开的连接
+func (m *poolMetrics) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := m.dial(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.open++
+	m.mu.Unlock()
+
+	return &countedConn{Conn: conn, closed: func() {
+		m.mu.Lock()
+		m.open--
+		m.mu.Unlock()
+	}}, nil
+}
+
+func (m *poolMetrics) host(host string) *HostPoolStats {
+	h, ok := m.hosts[host]
+	if !ok {
+		h = &HostPoolStats{}
+		m.hosts[host] = h
+	}
+	return h
+}
+
+// 为请求添加 httptrace, 返回的 done 在请求结束(响应体关闭或出错)时调用
+func (m *poolMetrics) trace(req *http.Request) (*http.Request, func()) {
+	host := req.URL.Host
+	start := time.Now()
+
+	m.mu.Lock()
+	h := m.host(host)
+	h.Requests++
+	h.InFlight++
+	m.mu.Unlock()
+
+	var dnsStart, connectStart, tlsStart time.Time
+	gotConn := false
+
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			m.mu.Lock()
+			dnsStart = time.Now()
+			m.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			m.mu.Lock()
+			if !dnsStart.IsZero() {
+				m.dns.observe(time.Since(dnsStart))
+			}
+			m.mu.Unlock()
+		},
+		ConnectStart: func(network, addr string) {
+			m.mu.Lock()
+			connectStart = time.Now()
+			m.mu.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			m.mu.Lock()
+			if err == nil && !connectStart.IsZero() {
+				m.connect.observe(time.Since(connectStart))
+			}
+			m.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			m.mu.Lock()
+			tlsStart = time.Now()
+			m.mu.Unlock()
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			m.mu.Lock()
+			if err == nil && !tlsStart.IsZero() {
+				m.tls.observe(time.Since(tlsStart))
+			}
+			m.mu.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+
+			h := m.host(host)
+			if info.Reused {
+				h.ConnsReused++
+			} else {
+				h.ConnsCreated++
+			}
+			if info.WasIdle {
+				h.IdleReused++
+			}
+			// 重定向时同一个请求会多次获取连接, 只计一次
+			if !gotConn {
+				gotConn = true
+				m.active++
+			}
+		},
+		GotFirstResponseByte: func() {
+			m.mu.Lock()
+			m.ttfb.observe(time.Since(start))
+			m.mu.Unlock()
+		},
+	}
+
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.host(host).InFlight--
+			if gotConn {
+				m.active--
+			}
+		})
+	}
+
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), done
+}
+
+func (m *poolMetrics) stats() PoolStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := PoolStats{
+		OpenConns:   m.open,
+		ActiveConns: m.active,
+		DNS:         m.dns,
+		Connect:     m.connect,
+		TLS:         m.tls,
+		TTFB:        m.ttfb,
+		Hosts:       make(map[string]HostPoolStats, len(m.hosts)),
+	}
+	if idle := m.open - m.active; idle > 0 {
+		stats.IdleConns = idle
+	}
+
+	for host, h := range m.hosts {
+		stats.Hosts[host] = *h
+		stats.Requests += h.Requests
+		stats.InFlight += h.InFlight
+		stats.ConnsCreated += h.ConnsCreated
+		stats.ConnsReused += h.ConnsReused
+		stats.IdleReused += h.IdleReused
+	}
+	return stats
+}
+
+// 关闭时回调的连接
+type countedConn struct {
+	net.Conn
+	once   sync.Once
+	closed func()
+}
+
+func (c *countedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.closed)
+	return err
+}
+
+// 关闭时回调的响应体
+type notifyBody struct {
+	io.ReadCloser
+	once   sync.Once
+	closed func()
+}
+
+func (b *notifyBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.closed)
+	return err
+}
+
+// 发送请求并记录连接池指标
+func (c *Client) roundTrip(httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	if c.pool == nil {
+		return httpClient.Do(req)
+	}
+
+	traced, done := c.pool.trace(req)
+	resp, err := httpClient.Do(traced)
+	if err != nil {
+		done()
+		return resp, err
+	}
+	resp.Body = &notifyBody{ReadCloser: resp.Body, closed: done}
+	return resp, nil
+}
+
+// 获取连接池的实时统计
+func (c *Client) PoolStats() PoolStats {
+	var stats PoolStats
+	if c.pool != nil {
+		stats = c.pool.stats()
+	}
+
+	stats.MaxIdleConns = c.maxIdleConns
+	stats.MaxConnsPerHost = c.maxConnsPerHost
+	stats.MaxIdleConnsPerHost = c.maxIdleConnsPerHost
+	stats.IdleConnTimeout = c.idleConnTimeout
+	if transport := c.transport; transport != nil {
+		stats.MaxIdleConns = transport.MaxIdleConns
+		stats.MaxConnsPerHost = transport.MaxConnsPerHost
+		stats.MaxIdleConnsPerHost = transport.MaxIdleConnsPerHost
+		stats.IdleConnTimeout = transport.IdleConnTimeout
+	}
+	return stats
+}
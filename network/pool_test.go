@@ -0,0 +1,74 @@
+package network
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// 测试连接的新建、复用和空闲统计
+func TestClient_PoolStats(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	defer server.Close()
+
+	client := NewClient()
+	for i := 0; i < 3; i++ {
+		if _, err := client.Request("GET", server.URL, nil, 0); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+	}
+
+	stats := client.PoolStats()
+	host, _ := url.Parse(server.URL)
+	h := stats.Hosts[host.Host]
+	if h.Requests != 3 || h.InFlight != 0 || h.ConnsCreated != 1 || h.ConnsReused != 2 || h.IdleReused != 2 {
+		t.Errorf("Unexpected host stats %+v", h)
+	}
+
+	if stats.OpenConns != 1 || stats.ActiveConns != 0 || stats.IdleConns != 1 {
+		t.Errorf("Unexpected conn stats open=%d active=%d idle=%d", stats.OpenConns, stats.ActiveConns, stats.IdleConns)
+	}
+
+	if stats.Connect.Count != 1 || stats.TTFB.Count != 3 || stats.TTFB.Avg() <= 0 || stats.TTFB.Max < stats.TTFB.Avg() {
+		t.Errorf("Unexpected latency stats connect=%+v ttfb=%+v", stats.Connect, stats.TTFB)
+	}
+
+	if stats.MaxIdleConnsPerHost != 10 {
+		t.Errorf("Expected pool config in stats, got %d", stats.MaxIdleConnsPerHost)
+	}
+
+	client.Close()
+	if open := client.PoolStats().OpenConns; open != 0 {
+		t.Errorf("Expected idle connections to be closed, got %d", open)
+	}
+}
+
+// 测试流式响应在关闭前计为进行中, 以及 TLS 握手耗时
+func TestClient_PoolStats_InFlight(t *testing.T) {
+	server := createTLSServer()
+	defer server.Close()
+
+	client := NewClient(WithCACertPEM(serverCertPEM(server)))
+	stream, err := client.Stream(context.Background(), "GET", server.URL, nil)
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	stats := client.PoolStats()
+	if stats.InFlight != 1 || stats.ActiveConns != 1 || stats.TLS.Count != 1 {
+		t.Errorf("Unexpected stats before close inflight=%d active=%d tls=%+v", stats.InFlight, stats.ActiveConns, stats.TLS)
+	}
+
+	stream.Close()
+	if stats := client.PoolStats(); stats.InFlight != 0 || stats.ActiveConns != 0 {
+		t.Errorf("Unexpected stats after close inflight=%d active=%d", stats.InFlight, stats.ActiveConns)
+	}
+
+	// Clone 使用独立的统计
+	if clone := client.Clone(); clone.PoolStats().Requests != 0 {
+		t.Error("Expected clone to have separate pool stats")
+	}
+}
@@ -0,0 +1,203 @@
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 默认的耗时分桶(秒), 与 Prometheus 客户端一致
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// PrometheusExporter 汇总请求指标并以 Prometheus 文本格式导出, 可被多个客户端共用
+/* example
+exporter := network.NewPrometheusExporter("myapp")
+client := network.NewClient(network.WithMetrics(exporter))
+http.Handle("/metrics", exporter)
+*/
+type PrometheusExporter struct {
+	namespace string
+	buckets   []float64
+
+	mu       sync.Mutex
+	requests map[requestLabels]int64
+	errors   map[errorLabels]int64
+	latency  map[latencyLabels]*histogram
+}
+
+type requestLabels struct {
+	method, host, status string
+}
+
+type errorLabels struct {
+	method, host, code string
+}
+
+type latencyLabels struct {
+	method, host string
+}
+
+// 直方图, counts[i] 为耗时 <= buckets[i] 的次数
+type histogram struct {
+	counts []int64
+	count  int64
+	sum    float64
+}
+
+// 创建导出器, namespace 为指标名前缀, buckets 为空时使用 DefaultLatencyBuckets
+func NewPrometheusExporter(namespace string, buckets ...float64) *PrometheusExporter {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &PrometheusExporter{
+		namespace: namespace,
+		buckets:   buckets,
+		requests:  map[requestLabels]int64{},
+		errors:    map[errorLabels]int64{},
+		latency:   map[latencyLabels]*histogram{},
+	}
+}
+
+// Observe 实现 MetricsHook
+func (e *PrometheusExporter) Observe(m RequestMetric) {
+	seconds := m.Duration.Seconds()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.requests[requestLabels{m.Method, m.Host, strconv.Itoa(m.StatusCode)}]++
+	if m.ErrorCode != "" {
+		e.errors[errorLabels{m.Method, m.Host, m.ErrorCode}]++
+	}
+
+	key := latencyLabels{m.Method, m.Host}
+	h, ok := e.latency[key]
+	if !ok {
+		h = &histogram{counts: make([]int64, len(e.buckets))}
+		e.latency[key] = h
+	}
+	for i, bound := range e.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// ServeHTTP 实现 http.Handler, 输出 Prometheus 文本格式
+func (e *PrometheusExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(ContentType, "text/plain; version=0.0.4; charset=utf-8")
+	e.WriteTo(w)
+}
+
+// WriteTo 将指标以 Prometheus 文本格式写入 w
+func (e *PrometheusExporter) WriteTo(w io.Writer) (int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	name := e.name("http_client_requests_total")
+	fmt.Fprintf(bw, "# HELP %s Total number of HTTP client calls.\n# TYPE %s counter\n", name, name)
+	requests := make([]requestLabels, 0, len(e.requests))
+	for k := range e.requests {
+		requests = append(requests, k)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		a, b := requests[i], requests[j]
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		if a.host != b.host {
+			return a.host < b.host
+		}
+		return a.status < b.status
+	})
+	for _, k := range requests {
+		fmt.Fprintf(bw, "%s{method=%s,host=%s,status=%s} %d\n", name, quote(k.method), quote(k.host), quote(k.status), e.requests[k])
+	}
+
+	name = e.name("http_client_errors_total")
+	fmt.Fprintf(bw, "# HELP %s Total number of failed HTTP client calls by error code.\n# TYPE %s counter\n", name, name)
+	errs := make([]errorLabels, 0, len(e.errors))
+	for k := range e.errors {
+		errs = append(errs, k)
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		a, b := errs[i], errs[j]
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		if a.host != b.host {
+			return a.host < b.host
+		}
+		return a.code < b.code
+	})
+	for _, k := range errs {
+		fmt.Fprintf(bw, "%s{method=%s,host=%s,code=%s} %d\n", name, quote(k.method), quote(k.host), quote(k.code), e.errors[k])
+	}
+
+	name = e.name("http_client_request_duration_seconds")
+	fmt.Fprintf(bw, "# HELP %s HTTP client call latency in seconds.\n# TYPE %s histogram\n", name, name)
+	latency := make([]latencyLabels, 0, len(e.latency))
+	for k := range e.latency {
+		latency = append(latency, k)
+	}
+	sort.Slice(latency, func(i, j int) bool {
+		a, b := latency[i], latency[j]
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.host < b.host
+	})
+	for _, k := range latency {
+		h := e.latency[k]
+		labels := fmt.Sprintf("method=%s,host=%s", quote(k.method), quote(k.host))
+		for i, bound := range e.buckets {
+			fmt.Fprintf(bw, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(bw, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(bw, "%s_sum{%s} %s\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(bw, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// 添加命名空间前缀
+func (e *PrometheusExporter) name(metric string) string {
+	if e.namespace == "" {
+		return metric
+	}
+	return e.namespace + "_" + metric
+}
+
+// 标签值转义
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quote(value string) string {
+	return `"` + labelReplacer.Replace(value) + `"`
+}
+
+// 统计写入字节数
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,273 @@
+package network
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// 本地代理: 转发 CONNECT 隧道, 普通请求直接返回代理收到的地址
+func createProxyServer(t *testing.T, auth string, connects *int32) *httptest.Server {
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth != "" && r.Header.Get("Proxy-Authorization") != want {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+
+		if r.Method != http.MethodConnect {
+			w.Write([]byte("proxied " + r.URL.String()))
+			return
+		}
+
+		atomic.AddInt32(connects, 1)
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack failed: %v", err)
+			return
+		}
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go pipeConn(conn, upstream)
+	}))
+}
+
+func pipeConn(a, b net.Conn) {
+	go func() {
+		io.Copy(a, b)
+		a.Close()
+	}()
+	io.Copy(b, a)
+	b.Close()
+}
+
+// 测试 HTTP 代理认证和 NoProxy
+func TestClient_Proxy_HTTP(t *testing.T) {
+	proxy := createProxyServer(t, "u:p", nil)
+	defer proxy.Close()
+
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("direct"))
+	})
+	defer server.Close()
+
+	client := NewClient(WithProxyConfig(ProxyConfig{
+		HTTP:     proxy.URL,
+		Username: "u",
+		Password: "p",
+		NoProxy:  []string{"127.0.0.1"},
+	}))
+
+	response, err := client.Request("GET", "http://example.test/a?b=1", nil, 0)
+	if err != nil || response.ToString() != "proxied http://example.test/a?b=1" {
+		t.Fatalf("Expected request via proxy, got %v '%s'", err, response.ToString())
+	}
+
+	response, err = client.Request("GET", server.URL, nil, 0)
+	if err != nil || response.ToString() != "direct" {
+		t.Errorf("Expected NoProxy host to be requested directly, got %v '%s'", err, response.ToString())
+	}
+
+	// 认证失败
+	response, err = NewClient(WithProxy(proxy.URL)).Request("GET", "http://example.test/", nil, 0)
+	if err == nil || response.StatusCode != http.StatusProxyAuthRequired {
+		t.Errorf("Expected 407 without credentials, got %v %d", err, response.StatusCode)
+	}
+}
+
+// 测试 HTTPS 请求通过 CONNECT 隧道
+func TestClient_Proxy_Connect(t *testing.T) {
+	var connects int32
+	proxy := createProxyServer(t, "u:p", &connects)
+	defer proxy.Close()
+
+	server := createTLSServer()
+	defer server.Close()
+
+	u := "http://u:p@" + proxy.Listener.Addr().String()
+	response, err := NewClient(WithProxy(u), WithInsecureSkipVerify()).Request("GET", server.URL, nil, 0)
+	if err != nil || response.ToString() != "ok" {
+		t.Fatalf("Expected request via CONNECT tunnel, got %v", err)
+	}
+	if atomic.LoadInt32(&connects) != 1 {
+		t.Errorf("Expected 1 CONNECT, got %d", connects)
+	}
+}
+
+// 本地 SOCKS5 代理, 要求用户名密码认证
+func createSOCKS5Server(t *testing.T, user, pass string) net.Listener {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSOCKS5(conn, user, pass)
+		}
+	}()
+	return ln
+}
+
+func serveSOCKS5(conn net.Conn, user, pass string) {
+	buf := make([]byte, 262)
+
+	// 协商认证方式: 仅支持用户名密码
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		conn.Close()
+		return
+	}
+	io.ReadFull(conn, buf[:buf[1]])
+	conn.Write([]byte{5, 2})
+
+	io.ReadFull(conn, buf[:2])
+	u := make([]byte, buf[1])
+	io.ReadFull(conn, u)
+	io.ReadFull(conn, buf[:1])
+	p := make([]byte, buf[0])
+	io.ReadFull(conn, p)
+	if string(u) != user || string(p) != pass {
+		conn.Write([]byte{1, 1})
+		conn.Close()
+		return
+	}
+	conn.Write([]byte{1, 0})
+
+	// CONNECT 请求
+	io.ReadFull(conn, buf[:4])
+	var host string
+	switch buf[3] {
+	case 1:
+		io.ReadFull(conn, buf[:4])
+		host = net.IP(buf[:4]).String()
+	case 3:
+		io.ReadFull(conn, buf[:1])
+		name := make([]byte, buf[0])
+		io.ReadFull(conn, name)
+		host = string(name)
+	}
+	io.ReadFull(conn, buf[:2])
+	port := binary.BigEndian.Uint16(buf[:2])
+
+	upstream, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))))
+	if err != nil {
+		conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		conn.Close()
+		return
+	}
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	pipeConn(conn, upstream)
+}
+
+// 测试 SOCKS5 代理
+func TestClient_Proxy_SOCKS5(t *testing.T) {
+	ln := createSOCKS5Server(t, "u", "p")
+	defer ln.Close()
+
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	defer server.Close()
+
+	response, err := NewClient(WithProxy("socks5://u:p@"+ln.Addr().String())).Request("GET", server.URL, nil, 0)
+	if err != nil || response.ToString() != "ok" {
+		t.Fatalf("Expected request via socks5, got %v", err)
+	}
+
+	if _, err := NewClient(WithProxy("socks5://u:x@"+ln.Addr().String())).Request("GET", server.URL, nil, 0); err == nil {
+		t.Error("Expected socks5 authentication failure")
+	}
+}
+
+// 测试从环境变量读取代理
+func TestClient_ProxyFromEnvironment(t *testing.T) {
+	proxy := createProxyServer(t, "", nil)
+	defer proxy.Close()
+
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("direct"))
+	})
+	defer server.Close()
+
+	t.Setenv("HTTP_PROXY", proxy.Listener.Addr().String())
+	t.Setenv("NO_PROXY", "skip.test, .internal.test")
+	t.Setenv("REQUEST_METHOD", "")
+
+	client := NewClient(WithProxyFromEnvironment())
+	response, err := client.Request("GET", "http://example.test/", nil, 0)
+	if err != nil || response.ToString() != "proxied http://example.test/" {
+		t.Fatalf("Expected request via proxy, got %v '%s'", err, response.ToString())
+	}
+
+	// 回环地址不使用代理
+	if response, _ := client.Request("GET", server.URL, nil, 0); response.ToString() != "direct" {
+		t.Errorf("Expected loopback to bypass proxy, got '%s'", response.ToString())
+	}
+
+	// 关闭代理后 example.test 无法解析
+	client.SetProxy(nil)
+	if _, err := client.Request("GET", "http://example.test/", nil, 0); err == nil {
+		t.Error("Expected direct request to fail after SetProxy(nil)")
+	}
+}
+
+// 测试 NoProxy 规则
+func TestProxySelector_NoProxy(t *testing.T) {
+	selector, err := newProxySelector(ProxyConfig{
+		HTTP:    "http://proxy:3128",
+		HTTPS:   "socks5h://proxy:1080",
+		NoProxy: []string{"example.com", ".sub.test", "*.wild.test", "10.0.0.0/8", "192.168.1.1", "api.test:8443", "[::2]"},
+	})
+	if err != nil {
+		t.Fatalf("newProxySelector failed: %v", err)
+	}
+
+	cases := map[string]bool{
+		"http://example.com":        false,
+		"http://a.example.com":      false,
+		"http://notexample.com":     true,
+		"http://sub.test":           true,
+		"http://a.sub.test":         false,
+		"http://a.wild.test":        false,
+		"http://10.1.2.3":           false,
+		"http://11.1.2.3":           true,
+		"http://192.168.1.1:8080":   false,
+		"https://api.test:8443":     false,
+		"https://api.test":          true,
+		"http://[::2]/":             false,
+		"https://other.test/path?q": true,
+	}
+	for rawURL, proxied := range cases {
+		req, _ := http.NewRequest("GET", rawURL, nil)
+		u, _ := selector.proxy(req)
+		if (u != nil) != proxied {
+			t.Errorf("%s: expected proxied=%v, got %v", rawURL, proxied, u)
+		}
+		if u != nil && req.URL.Scheme == "https" && u.Scheme != "socks5h" {
+			t.Errorf("%s: expected https proxy, got %s", rawURL, u)
+		}
+	}
+
+	for _, config := range []ProxyConfig{{HTTP: "ftp://proxy"}, {HTTPS: "http://"}, {NoProxy: []string{"10.0.0.0/33"}}} {
+		if _, err := newProxySelector(config); err == nil {
+			t.Errorf("Expected error for %+v", config)
+		}
+		if err := NewClient().SetProxy(&config); err == nil {
+			t.Errorf("Expected SetProxy error for %+v", config)
+		}
+	}
+}
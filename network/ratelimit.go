@@ -0,0 +1,228 @@
+package network
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit 令牌桶限流
+type RateLimit struct {
+	Rate  float64 // 每秒生成的令牌数, 为 0 时不限流
+	Burst int     // 桶容量, 默认为 Rate 向上取整(至少为 1)
+}
+
+// RateLimitConfig 限流配置
+/* example
+client := network.NewClient(network.WithRateLimit(network.RateLimitConfig{
+	Global:  network.RateLimit{Rate: 100},
+	PerHost: map[string]network.RateLimit{"api.vendor.com": {Rate: 5, Burst: 1}},
+}))
+*/
+type RateLimitConfig struct {
+	Global  RateLimit            // 全局限流
+	PerHost map[string]RateLimit // 按 host 限流, key 可以带端口
+	Default RateLimit            // 未在 PerHost 中配置的 host 的限流
+
+	// 不根据响应头(Retry-After、X-RateLimit-Remaining、X-RateLimit-Reset)调整限流
+	IgnoreHeaders bool
+}
+
+// 令牌桶, 支持预约令牌和暂停
+type tokenBucket struct {
+	rate         float64
+	burst        float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time // 服务端要求暂停到的时间
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	b := &tokenBucket{rate: limit.Rate, burst: float64(limit.Burst), last: time.Now()}
+	if b.burst <= 0 {
+		b.burst = math.Max(1, math.Ceil(limit.Rate))
+	}
+	b.tokens = b.burst
+	return b
+}
+
+// 按时间补充令牌
+func (b *tokenBucket) advance(now time.Time) {
+	if b.rate > 0 && now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+}
+
+// 预约一个令牌, 返回需要等待的时间
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	var wait time.Duration
+	if b.rate > 0 {
+		b.advance(now)
+		b.tokens--
+		if b.tokens < 0 {
+			wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+		}
+	}
+	if blocked := b.blockedUntil.Sub(now); blocked > wait {
+		wait = blocked
+	}
+	return wait
+}
+
+// 取消预约, 归还令牌
+func (b *tokenBucket) cancel(now time.Time) {
+	if b.rate > 0 {
+		b.advance(now)
+		b.tokens = math.Min(b.burst, b.tokens+1)
+	}
+}
+
+// 按 host 限流
+type rateLimiter struct {
+	config RateLimitConfig
+	mu     sync.Mutex
+	global *tokenBucket
+	hosts  map[string]*tokenBucket
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	l := &rateLimiter{config: config, hosts: map[string]*tokenBucket{}}
+	if config.Global.Rate > 0 {
+		l.global = newTokenBucket(config.Global)
+	}
+	return l
+}
+
+// host 对应的令牌桶, 未配置限流的 host 也会创建令牌桶以便根据响应头暂停
+func (l *rateLimiter) host(host string) *tokenBucket {
+	host = strings.ToLower(host)
+	b, ok := l.hosts[host]
+	if !ok {
+		limit, ok := l.config.PerHost[host]
+		if !ok {
+			// 不带端口匹配
+			if i := strings.LastIndex(host, ":"); i > 0 {
+				limit, ok = l.config.PerHost[host[:i]]
+			}
+		}
+		if !ok {
+			limit = l.config.Default
+		}
+		b = newTokenBucket(limit)
+		l.hosts[host] = b
+	}
+	return b
+}
+
+// 等待全局和 host 的令牌, ctx 结束时归还令牌并返回错误
+func (l *rateLimiter) wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	now := time.Now()
+	hb := l.host(host)
+	wait := hb.reserve(now)
+	if l.global != nil {
+		if w := l.global.reserve(now); w > wait {
+			wait = w
+		}
+	}
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		now := time.Now()
+		hb.cancel(now)
+		if l.global != nil {
+			l.global.cancel(now)
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// 根据响应头调整 host 的限流
+func (l *rateLimiter) update(host string, resp *http.Response) {
+	if l.config.IgnoreHeaders || resp == nil {
+		return
+	}
+
+	var pause time.Duration
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		pause, _ = ParseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	hasRemaining := err == nil && remaining >= 0
+	if hasRemaining && remaining == 0 {
+		if reset, ok := parseRateLimitReset(resp.Header.Get("X-RateLimit-Reset")); ok && reset > pause {
+			pause = reset
+		}
+	}
+
+	if pause <= 0 && !hasRemaining {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b := l.host(host)
+	if until := now.Add(pause); until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+	// 剩余配额少于本地令牌时以服务端为准
+	if hasRemaining && b.rate > 0 {
+		b.advance(now)
+		b.tokens = math.Min(b.tokens, float64(remaining))
+	}
+}
+
+// 解析 X-RateLimit-Reset, 支持剩余秒数和 Unix 时间戳
+func parseRateLimitReset(value string) (time.Duration, bool) {
+	reset, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || reset < 0 {
+		return 0, false
+	}
+
+	// 大于一年的秒数视为 Unix 时间戳
+	if reset > 365*24*3600 {
+		d := time.Until(time.Unix(reset, 0))
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return time.Duration(reset) * time.Second, true
+}
+
+// 开启客户端限流
+func WithRateLimit(config RateLimitConfig) Option {
+	return func(c *Client) error {
+		c.SetRateLimit(&config)
+		return nil
+	}
+}
+
+// 设置限流, 为 nil 时关闭限流
+func (c *Client) SetRateLimit(config *RateLimitConfig) {
+	if config == nil {
+		c.limiter = nil
+		return
+	}
+	c.limiter = newRateLimiter(*config)
+}
@@ -0,0 +1,106 @@
+package network
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 测试按 host 的令牌桶限流
+func TestClient_RateLimit(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	defer server.Close()
+
+	client := NewClient(WithRateLimit(RateLimitConfig{
+		Global:  RateLimit{Rate: 1000},
+		Default: RateLimit{Rate: 20, Burst: 1},
+	}))
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := client.Request("GET", server.URL, nil, 0); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("Expected requests to be paced at 20/s, took %v", elapsed)
+	}
+
+	// 等待令牌时 ctx 结束
+	client.SetRateLimit(&RateLimitConfig{PerHost: map[string]RateLimit{"127.0.0.1": {Rate: 1}}})
+	client.Request("GET", server.URL, nil, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start = time.Now()
+	if _, err := client.RequestWithContext(ctx, "GET", server.URL, nil, 0); err == nil {
+		t.Error("Expected error when context ends while waiting")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected wait to respect context, took %v", elapsed)
+	}
+}
+
+// 测试根据 Retry-After 和 X-RateLimit-Remaining 暂停请求
+func TestClient_RateLimit_Headers(t *testing.T) {
+	var calls int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Second).Unix()+1, 10))
+			w.Write([]byte("ok"))
+		default:
+			w.Write([]byte("ok"))
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(WithRateLimit(RateLimitConfig{}))
+	client.Request("GET", server.URL, nil, 0)
+
+	start := time.Now()
+	if _, err := client.Request("GET", server.URL, nil, 0); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("Expected Retry-After to pause requests, took %v", elapsed)
+	}
+
+	start = time.Now()
+	client.Request("GET", server.URL, nil, 0)
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("Expected exhausted quota to pause until reset, took %v", elapsed)
+	}
+
+	// 忽略响应头
+	client.SetRateLimit(&RateLimitConfig{IgnoreHeaders: true})
+	atomic.StoreInt32(&calls, 0)
+	client.Request("GET", server.URL, nil, 0)
+	start = time.Now()
+	client.Request("GET", server.URL, nil, 0)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected headers to be ignored, took %v", elapsed)
+	}
+}
+
+func TestParseRateLimitReset(t *testing.T) {
+	if d, ok := parseRateLimitReset("30"); !ok || d != 30*time.Second {
+		t.Errorf("Unexpected delta reset %v", d)
+	}
+	if d, ok := parseRateLimitReset(strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)); !ok || d < 58*time.Second || d > time.Minute {
+		t.Errorf("Unexpected epoch reset %v", d)
+	}
+	if _, ok := parseRateLimitReset("soon"); ok {
+		t.Error("Expected invalid reset to be rejected")
+	}
+}
@@ -0,0 +1,153 @@
+package network
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy 重试策略
+// attempt 为已失败的次数(从1开始), elapsed 为第一次请求开始至今的耗时
+// resp 和 err 为本次请求的结果, 返回需要等待的时间以及是否继续重试
+type RetryPolicy interface {
+	Retry(attempt int, elapsed time.Duration, req *http.Request, resp *http.Response, err error) (time.Duration, bool)
+}
+
+// RetryPolicyFunc 函数形式的重试策略
+type RetryPolicyFunc func(attempt int, elapsed time.Duration, req *http.Request, resp *http.Response, err error) (time.Duration, bool)
+
+// Retry 实现 RetryPolicy
+func (f RetryPolicyFunc) Retry(attempt int, elapsed time.Duration, req *http.Request, resp *http.Response, err error) (time.Duration, bool) {
+	return f(attempt, elapsed, req, resp, err)
+}
+
+// 默认可重试的状态码
+var defaultRetryableStatus = map[int]bool{
+	http.StatusRequestTimeout:      true, // 408
+	http.StatusTooEarly:            true, // 425
+	http.StatusTooManyRequests:     true, // 429
+	http.StatusInternalServerError: true, // 500
+	http.StatusBadGateway:          true, // 502
+	http.StatusServiceUnavailable:  true, // 503
+	http.StatusGatewayTimeout:      true, // 504
+}
+
+// ExponentialBackoff 指数退避重试策略
+type ExponentialBackoff struct {
+	MaxRetries       int                   // 最大重试次数
+	InitialInterval  time.Duration         // 第一次重试的等待时间
+	MaxInterval      time.Duration         // 单次等待的上限, Retry-After 超过该值时不再重试, 0 表示不限制
+	Multiplier       float64               // 每次重试等待时间的增长倍数
+	Jitter           float64               // 抖动比例 [0,1], 等待时间在 interval*(1±Jitter) 之间随机
+	MaxElapsedTime   time.Duration         // 总耗时上限, 0 表示不限制
+	RetryableStatus  func(status int) bool // 判断状态码是否可重试, nil 时使用默认状态码集合
+	IdempotentOnly   bool                  // 只重试幂等方法(GET/HEAD/OPTIONS/TRACE/PUT/DELETE)
+	IgnoreRetryAfter bool                  // 忽略响应头 Retry-After
+}
+
+// 根据重试次数生成默认策略(兼容 Request 的 retry 参数)
+func DefaultRetryPolicy(retry int) *ExponentialBackoff {
+	return &ExponentialBackoff{
+		MaxRetries:      retry,
+		InitialInterval: 200 * time.Millisecond,
+		MaxInterval:     5 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+	}
+}
+
+// Retry 实现 RetryPolicy
+func (p *ExponentialBackoff) Retry(attempt int, elapsed time.Duration, req *http.Request, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt > p.MaxRetries {
+		return 0, false
+	}
+
+	if p.IdempotentOnly && req != nil && !IsIdempotent(req.Method) {
+		return 0, false
+	}
+
+	// 有响应时根据状态码判断是否重试
+	if err == nil && resp != nil && !p.retryable(resp.StatusCode) {
+		return 0, false
+	}
+
+	wait := p.interval(attempt)
+
+	// 服务端指定了重试时间, 超过 MaxInterval 时不提前重试
+	if !p.IgnoreRetryAfter && resp != nil {
+		if d, ok := ParseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if p.MaxInterval > 0 && d > p.MaxInterval {
+				return 0, false
+			}
+			wait = d
+		}
+	}
+
+	if p.MaxElapsedTime > 0 && elapsed+wait > p.MaxElapsedTime {
+		return 0, false
+	}
+
+	return wait, true
+}
+
+func (p *ExponentialBackoff) retryable(status int) bool {
+	if p.RetryableStatus != nil {
+		return p.RetryableStatus(status)
+	}
+	return defaultRetryableStatus[status]
+}
+
+// 计算第 attempt 次重试的等待时间
+func (p *ExponentialBackoff) interval(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	wait := float64(p.InitialInterval) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxInterval > 0 && wait > float64(p.MaxInterval) {
+		wait = float64(p.MaxInterval)
+	}
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		wait = wait * (1 - jitter + 2*jitter*rand.Float64())
+	}
+
+	return time.Duration(wait)
+}
+
+// 判断HTTP方法是否幂等
+func IsIdempotent(method string) bool {
+	switch method {
+	case GET, HEAD, OPTIONS, TRACE, PUT, DELETE:
+		return true
+	}
+	return false
+}
+
+// 解析 Retry-After 响应头, 支持秒数和HTTP日期两种格式
+func ParseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
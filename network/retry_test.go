@@ -0,0 +1,143 @@
+package network
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 测试指数退避的等待时间
+func TestExponentialBackoff_Interval(t *testing.T) {
+	p := &ExponentialBackoff{
+		MaxRetries:      5,
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     300 * time.Millisecond,
+		Multiplier:      2,
+	}
+
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, w := range want {
+		got, ok := p.Retry(i+1, 0, nil, nil, errors.New("network error"))
+		if !ok {
+			t.Fatalf("attempt %d: expected retry", i+1)
+		}
+		if got != w {
+			t.Errorf("attempt %d: expected wait %v, got %v", i+1, w, got)
+		}
+	}
+
+	if _, ok := p.Retry(6, 0, nil, nil, errors.New("network error")); ok {
+		t.Error("Expected no retry after MaxRetries")
+	}
+}
+
+// 测试抖动范围
+func TestExponentialBackoff_Jitter(t *testing.T) {
+	p := &ExponentialBackoff{MaxRetries: 1, InitialInterval: 100 * time.Millisecond, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		got, _ := p.Retry(1, 0, nil, nil, errors.New("network error"))
+		if got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("wait %v out of jitter range", got)
+		}
+	}
+}
+
+// 测试状态码、幂等方法和总耗时限制
+func TestExponentialBackoff_Conditions(t *testing.T) {
+	p := &ExponentialBackoff{MaxRetries: 3, InitialInterval: time.Second, IdempotentOnly: true, MaxElapsedTime: 3 * time.Second}
+
+	get, _ := http.NewRequest(GET, "http://example.com", nil)
+	post, _ := http.NewRequest(POST, "http://example.com", nil)
+
+	if _, ok := p.Retry(1, 0, get, &http.Response{StatusCode: 404}, nil); ok {
+		t.Error("404 should not be retried")
+	}
+	if _, ok := p.Retry(1, 0, get, &http.Response{StatusCode: 503}, nil); !ok {
+		t.Error("503 should be retried")
+	}
+	if _, ok := p.Retry(1, 0, post, &http.Response{StatusCode: 503}, nil); ok {
+		t.Error("POST should not be retried when IdempotentOnly")
+	}
+	if _, ok := p.Retry(1, 2500*time.Millisecond, get, &http.Response{StatusCode: 503}, nil); ok {
+		t.Error("Expected no retry beyond MaxElapsedTime")
+	}
+}
+
+// 测试 Retry-After 响应头
+func TestExponentialBackoff_RetryAfter(t *testing.T) {
+	p := &ExponentialBackoff{MaxRetries: 1, InitialInterval: time.Millisecond}
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"2"}}}
+
+	got, ok := p.Retry(1, 0, nil, resp, nil)
+	if !ok || got != 2*time.Second {
+		t.Errorf("Expected wait 2s from Retry-After, got %v (%v)", got, ok)
+	}
+
+	// Retry-After 超过 MaxInterval 或 MaxElapsedTime 时不重试
+	resp.Header.Set("Retry-After", "3600")
+	if got, ok := DefaultRetryPolicy(1).Retry(1, 0, nil, resp, nil); ok {
+		t.Errorf("Expected no retry when Retry-After exceeds MaxInterval, got %v", got)
+	}
+	resp.Header.Set("Retry-After", "3")
+	if got, ok := DefaultRetryPolicy(1).Retry(1, 0, nil, resp, nil); !ok || got != 3*time.Second {
+		t.Errorf("Expected wait 3s from Retry-After, got %v (%v)", got, ok)
+	}
+	limited := &ExponentialBackoff{MaxRetries: 1, MaxElapsedTime: 2 * time.Second}
+	if got, ok := limited.Retry(1, 0, nil, resp, nil); ok {
+		t.Errorf("Expected no retry when Retry-After exceeds MaxElapsedTime, got %v", got)
+	}
+
+	if d, ok := ParseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)); !ok || d != 0 {
+		t.Errorf("Expected 0 for past date, got %v (%v)", d, ok)
+	}
+
+	if _, ok := ParseRetryAfter("soon"); ok {
+		t.Error("Expected invalid Retry-After to be ignored")
+	}
+}
+
+// 测试客户端使用自定义重试策略
+func TestClient_SetRetryPolicy(t *testing.T) {
+	var attempts int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	client := NewClient()
+	client.SetRetryPolicy(&ExponentialBackoff{MaxRetries: 5, InitialInterval: time.Millisecond})
+
+	// 设置了策略后 retry 参数不再生效
+	if _, err := client.Request("GET", server.URL, nil, 0); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	if n := atomic.LoadInt32(&attempts); n != 3 {
+		t.Errorf("Expected 3 attempts, got %d", n)
+	}
+}
+
+// 测试默认策略不重试 4xx
+func TestClient_Request_NoRetryOn4xx(t *testing.T) {
+	var attempts int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	defer server.Close()
+
+	client := NewClient()
+	if _, err := client.Request("GET", server.URL, nil, 3); err == nil {
+		t.Fatal("Expected error for 400 status code")
+	}
+
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Errorf("Expected 1 attempt for 400, got %d", n)
+	}
+}
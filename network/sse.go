@@ -0,0 +1,261 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shzy2012/common/errors"
+	"github.com/shzy2012/common/tools"
+)
+
+// ErrStopSSE 在事件回调中返回该错误可正常结束订阅
+var ErrStopSSE = stdErrors.New("network: stop sse")
+
+// Event Server-Sent Events 事件
+type Event struct {
+	ID    string        // id 字段, 未设置时沿用上一个事件的 id
+	Event string        // event 字段, 默认为 message
+	Data  string        // data 字段, 多行以 \n 连接
+	Retry time.Duration // retry 字段, 未设置时为 0
+}
+
+// SSEOptions SSE 订阅选项
+type SSEOptions struct {
+	Method         string        // 请求方法, 默认 GET
+	Body           []byte        // 请求体(例如对话接口的 JSON 参数), 重连时重新发送
+	LastEventID    string        // 初始的 Last-Event-ID
+	MaxReconnects  int           // 连续重连失败(未收到事件)的最大次数, 收到事件后重新计数, 0 表示不重连
+	ReconnectDelay time.Duration // 重连等待时间, 默认 3s, 服务端的 retry 字段会覆盖该值
+	DoneData       string        // data 等于该值时结束订阅, 例如 OpenAI 的 [DONE]
+}
+
+/* 订阅 Server-Sent Events, 每收到一个事件回调一次 handler
+* ctx: Context
+* url:请求地址
+* opts:订阅选项, 可为 nil
+* handler:事件回调, 返回 ErrStopSSE 正常结束, 返回其他错误则中止订阅并返回该错误
+ */
+func (c *Client) SSE(ctx context.Context, url string, opts *SSEOptions, handler func(*Event) error) error {
+	if opts == nil {
+		opts = &SSEOptions{}
+	}
+
+	method := opts.Method
+	if method == "" {
+		method = GET
+	}
+
+	delay := opts.ReconnectDelay
+	if delay <= 0 {
+		delay = 3 * time.Second
+	}
+
+	lastID := opts.LastEventID
+	for failures := 0; ; failures++ {
+		received := false
+		done, err := c.sse(ctx, method, url, opts, &lastID, &delay, func(e *Event) error {
+			received = true
+			return handler(e)
+		})
+		if done {
+			return err
+		}
+
+		// 收到过事件的连接视为成功, 重新计算连续失败次数
+		if received {
+			failures = 0
+		}
+		if failures >= opts.MaxReconnects || ctx.Err() != nil {
+			if err == nil {
+				return nil
+			}
+			return err
+		}
+
+		if c.Debug {
+			c.logger.Debugf("[http_sse]=>reconnect %s after %s, last event id:%s\n", url, delay, lastID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+/* 订阅 Server-Sent Events, 通过 channel 接收事件
+* 事件 channel 在订阅结束后关闭, 错误 channel 随后收到订阅的最终结果(正常结束时为 nil)
+ */
+func (c *Client) SubscribeSSE(ctx context.Context, url string, opts *SSEOptions) (<-chan *Event, <-chan error) {
+	events := make(chan *Event)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		err := c.SSE(ctx, url, opts, func(e *Event) error {
+			select {
+			case events <- e:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		close(events)
+		errc <- err
+	}()
+
+	return events, errc
+}
+
+// 建立一次 SSE 连接并读取事件, done 为 true 时不再重连
+func (c *Client) sse(ctx context.Context, method, url string, opts *SSEOptions, lastID *string, delay *time.Duration, handler func(*Event) error) (done bool, err error) {
+	header := http.Header{}
+	header.Set("Accept", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	if *lastID != "" {
+		header.Set("Last-Event-ID", *lastID)
+	}
+
+	var body io.Reader
+	if opts.Body != nil {
+		body = bytes.NewReader(opts.Body)
+		header.Set("Content-Type", "application/json;charset=utf-8")
+	}
+
+	stream, err := c.stream(ctx, method, url, body, header)
+	if err != nil {
+		// 服务端明确拒绝时不重连
+		if _, ok := err.(*errors.ServerError); ok {
+			return true, err
+		}
+		return false, err
+	}
+	defer stream.Close()
+
+	if mediaType, _, _ := mime.ParseMediaType(stream.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		return true, errors.NewClientError(errors.UnsupportedTypeErrorCode, fmt.Sprintf(errors.UnsupportedTypeErrorMessage, mediaType, "text/event-stream"), nil)
+	}
+
+	reader := bufio.NewReader(stream.Body)
+	event := &Event{}
+	var data strings.Builder
+	hasData := false
+
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && (readErr != io.EOF || line == "") {
+			if readErr == io.EOF {
+				return false, nil
+			}
+			errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, readErr.Error())
+			return false, errors.NewClientError(errors.NetWorkErrorCode, errMsg, readErr)
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		// 空行: 分发事件
+		if line == "" {
+			if hasData {
+				event.ID = *lastID
+				event.Data = data.String()
+				if event.Event == "" {
+					event.Event = "message"
+				}
+
+				if opts.DoneData != "" && event.Data == opts.DoneData {
+					return true, nil
+				}
+
+				if err := handler(event); err != nil {
+					if err == ErrStopSSE {
+						return true, nil
+					}
+					return true, err
+				}
+			}
+			event = &Event{}
+			data.Reset()
+			hasData = false
+			continue
+		}
+
+		// 注释
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				*lastID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				event.Retry = time.Duration(ms) * time.Millisecond
+				*delay = event.Retry
+			}
+		}
+	}
+}
+
+// OpenAI 兼容接口的流式数据块
+type chatCompletionChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+/* 调用 OpenAI 兼容的流式对话接口
+* ctx: Context
+* url:接口地址, 例如 https://api.openai.com/v1/chat/completions
+* body:请求参数, 需包含 "stream": true
+* onDelta:每收到一段增量内容回调一次, 可为 nil
+* 返回经过 tools.CleanLLMOutput 处理后的完整内容
+ */
+func (c *Client) ChatCompletionStream(ctx context.Context, url string, body []byte, onDelta func(delta string)) (string, error) {
+	var content strings.Builder
+
+	opts := &SSEOptions{Method: POST, Body: body, DoneData: "[DONE]"}
+	err := c.SSE(ctx, url, opts, func(e *Event) error {
+		var chunk chatCompletionChunk
+		if err := json.Unmarshal([]byte(e.Data), &chunk); err != nil {
+			return errors.NewClientError(errors.JsonUnmarshalErrorCode, errors.JsonUnmarshalErrorMessage, err)
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if onDelta != nil {
+				onDelta(choice.Delta.Content)
+			}
+		}
+		return nil
+	})
+
+	return tools.CleanLLMOutput(content.String()), err
+}
@@ -0,0 +1,140 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 测试 SSE 事件解析
+func TestClient_SSE(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("Expected Accept text/event-stream, got '%s'", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": comment\n\n")
+		fmt.Fprint(w, "id: 1\ndata: hello\n\n")
+		fmt.Fprint(w, "event: update\r\ndata: line1\r\ndata:line2\r\n\r\n")
+		fmt.Fprint(w, "retry: 100\ndata: last\n\n")
+	})
+	defer server.Close()
+
+	var events []*Event
+	err := NewClient().SSE(context.Background(), server.URL, nil, func(e *Event) error {
+		events = append(events, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SSE failed: %v", err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+
+	if events[0].ID != "1" || events[0].Event != "message" || events[0].Data != "hello" {
+		t.Errorf("Unexpected event %+v", events[0])
+	}
+
+	if events[1].ID != "1" || events[1].Event != "update" || events[1].Data != "line1\nline2" {
+		t.Errorf("Unexpected event %+v", events[1])
+	}
+
+	if events[2].Retry != 100*time.Millisecond || events[2].Data != "last" {
+		t.Errorf("Unexpected event %+v", events[2])
+	}
+}
+
+// 测试断线重连携带 Last-Event-ID, 收到事件后重新计算重连次数
+func TestClient_SSE_Reconnect(t *testing.T) {
+	var connects int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch atomic.AddInt32(&connects, 1) {
+		case 1:
+			fmt.Fprint(w, "retry: 10\nid: 7\ndata: first\n\n")
+		case 2:
+			if r.Header.Get("Last-Event-ID") != "7" {
+				t.Errorf("Expected Last-Event-ID 7, got '%s'", r.Header.Get("Last-Event-ID"))
+			}
+			fmt.Fprint(w, "id: 8\ndata: second\n\n")
+		case 3:
+			fmt.Fprint(w, "id: 9\ndata: third\n\n")
+		}
+		// 之后的连接没有事件, 连续失败达到上限后结束
+	})
+	defer server.Close()
+
+	events, errc := NewClient().SubscribeSSE(context.Background(), server.URL, &SSEOptions{MaxReconnects: 1})
+
+	var data []string
+	for e := range events {
+		data = append(data, e.Data)
+	}
+
+	if err := <-errc; err != nil {
+		t.Fatalf("SSE failed: %v", err)
+	}
+
+	if strings.Join(data, ",") != "first,second,third" {
+		t.Errorf("Unexpected events %v", data)
+	}
+	if n := atomic.LoadInt32(&connects); n != 4 {
+		t.Errorf("Expected 4 connections, got %d", n)
+	}
+}
+
+// 测试非 event-stream 响应
+func TestClient_SSE_InvalidContentType(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	})
+	defer server.Close()
+
+	err := NewClient().SSE(context.Background(), server.URL, &SSEOptions{MaxReconnects: 3}, func(e *Event) error {
+		return nil
+	})
+	if err == nil {
+		t.Error("Expected error for non event-stream response")
+	}
+}
+
+// 测试 OpenAI 兼容的流式对话
+func TestClient_ChatCompletionStream(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != POST || !strings.Contains(string(body), `"stream":true`) {
+			t.Errorf("Unexpected request %s %s", r.Method, body)
+		}
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		for _, delta := range []string{"```", "你好", "，世界", "```"} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", delta)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+		fmt.Fprint(w, "data: ignored\n\n")
+	})
+	defer server.Close()
+
+	var deltas []string
+	content, err := NewClient().ChatCompletionStream(context.Background(), server.URL, []byte(`{"stream":true}`), func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+
+	if content != "你好，世界" {
+		t.Errorf("Expected cleaned content '你好，世界', got '%s'", content)
+	}
+
+	if len(deltas) != 4 {
+		t.Errorf("Expected 4 deltas, got %d", len(deltas))
+	}
+}
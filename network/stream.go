@@ -0,0 +1,320 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shzy2012/common/errors"
+	"github.com/shzy2012/common/tools"
+)
+
+// 非 2xx 响应时读取的最大错误信息长度
+const maxErrorBodySize = 64 << 10
+
+// StreamResponse 流式响应, 调用方负责读取并关闭 Body
+type StreamResponse struct {
+	StatusCode         int
+	Status             string
+	Header             http.Header
+	ContentLength      int64 // 未知时为 -1
+	Body               io.ReadCloser
+	OriginHTTPResponse *http.Response
+}
+
+// Close 关闭响应体, 连接返回连接池
+func (r *StreamResponse) Close() error {
+	if r.Body == nil {
+		return nil
+	}
+	return r.Body.Close()
+}
+
+/* 发起流式HTTP请求, 响应体不会被一次性读入内存
+* ctx: Context
+* method:POST\GET\PUT\PATCH\DELETE\HEAD\OPTIONS\TRACE\CONNECT
+* url:请求地址
+* body:请求体, 可为 nil
+ */
+func (c *Client) Stream(ctx context.Context, method, url string, body io.Reader) (*StreamResponse, error) {
+	return c.stream(ctx, method, url, body, nil)
+}
+
+// 发起流式请求, header 为本次请求额外设置的header
+func (c *Client) stream(ctx context.Context, method, url string, body io.Reader, header http.Header) (stream *StreamResponse, err error) {
+	response := &HTTPResponse{}
+
+	var req *http.Request
+	start := time.Now()
+	defer func() {
+		response.Elapsed = time.Since(start)
+		err = c.finish(req, strings.ToUpper(method), start, response, err)
+	}()
+
+	url, err = tools.URLCheck(c.resolveURL(url))
+	if err != nil {
+		errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
+		return nil, errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, strings.ToUpper(method), url, body)
+	if err != nil {
+		errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
+		return nil, errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
+	}
+
+	c.prepareRequest(req)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	c.setAcceptEncoding(req)
+
+	if err = c.beforeSend(req); err == nil {
+		err = c.wait(req)
+	}
+	if err != nil {
+		return nil, networkError(err)
+	}
+
+	var logID string
+	if c.Debug {
+		logID = c.trace().logID(req)
+		c.trace().logRequest(c.logger, logID, 1, req, nil)
+	}
+
+	// http.Client.Timeout 包含读取响应体的时间, 流式请求只对等待响应头的阶段计时
+	httpClient := *c.HttpClient
+	httpClient.Timeout = 0
+
+	ctx, cancel := context.WithCancel(req.Context())
+	req = req.WithContext(ctx)
+
+	var timer *time.Timer
+	if c.HttpClient.Timeout > 0 {
+		timer = time.AfterFunc(c.HttpClient.Timeout, cancel)
+	}
+
+	// 流式请求不对冲, 避免重复订阅或下载
+	resp, err := c.send(&httpClient, req, false)
+	if c.Debug {
+		c.trace().logResponse(c.logger, logID, 1, resp, err, time.Since(start))
+	}
+	if timer != nil && !timer.Stop() && err != nil {
+		err = fmt.Errorf("timeout awaiting response headers: %w", context.DeadlineExceeded)
+	}
+	if err != nil {
+		cancel()
+		return nil, networkError(err)
+	}
+	if err = c.decodeResponse(resp); err != nil {
+		resp.Body.Close()
+		cancel()
+		return nil, networkError(err)
+	}
+	resp.Body = &cancelReadCloser{ReadCloser: resp.Body, cancel: cancel}
+
+	response.setOrigin(resp)
+	response.Attempts = 1
+
+	// 非 2xx 时读取有限长度的错误信息并关闭响应体
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		response.ResponseBodyBytes = bodyBytes
+		response.Message = string(bodyBytes)
+		return nil, errors.NewServerError(resp.StatusCode, response.Message, nil)
+	}
+
+	return &StreamResponse{
+		StatusCode:         resp.StatusCode,
+		Status:             resp.Status,
+		Header:             resp.Header,
+		ContentLength:      resp.ContentLength,
+		Body:               resp.Body,
+		OriginHTTPResponse: resp,
+	}, nil
+}
+
+// 关闭响应体时释放请求的 context
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.cancel()
+	return err
+}
+
+// DownloadOptions 下载选项
+type DownloadOptions struct {
+	MaxBytes   int64                      // 允许下载的最大字节数, 0 表示不限制
+	Offset     int64                      // dst 中已有的字节数, 大于0时通过 Range 从该位置续传
+	MaxResumes int                        // 传输中断后通过 Range 续传的最大次数
+	Progress   func(written, total int64) // 进度回调, total 未知时为 -1
+}
+
+/* 下载文件并写入 dst, 返回本次写入的字节数
+* ctx: Context
+* url:下载地址
+* dst:写入目标
+* opts:下载选项, 可为 nil
+ */
+func (c *Client) Download(ctx context.Context, url string, dst io.Writer, opts *DownloadOptions) (int64, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+
+	w := &progressWriter{w: dst, written: opts.Offset, total: -1, progress: opts.Progress}
+	for resume := 0; ; resume++ {
+		err := c.download(ctx, url, w, opts.MaxBytes)
+		if err == nil {
+			return w.written - opts.Offset, nil
+		}
+
+		// 写入目标失败、超出大小限制或已取消时不再续传
+		if w.err != nil || resume >= opts.MaxResumes || ctx.Err() != nil {
+			return w.written - opts.Offset, err
+		}
+
+		if _, ok := err.(*errors.ServerError); ok {
+			return w.written - opts.Offset, err
+		}
+
+		if e, ok := err.(errors.Error); ok && e.ErrorCode() == errors.BodyTooLargeErrorCode {
+			return w.written - opts.Offset, err
+		}
+
+		if c.Debug {
+			c.logger.Debugf("[http_download]=>resume %s from %d: %s\n", url, w.written, err)
+		}
+	}
+}
+
+// 发起一次下载请求, 已写入内容时通过 Range 续传
+func (c *Client) download(ctx context.Context, url string, w *progressWriter, maxBytes int64) error {
+	header := http.Header{}
+	if w.written > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", w.written))
+	}
+
+	stream, err := c.stream(ctx, GET, url, nil, header)
+	if err != nil {
+		// 已经下载完整
+		if e, ok := err.(errors.Error); ok && e.HttpStatus() == http.StatusRequestedRangeNotSatisfiable && w.written > 0 {
+			return nil
+		}
+		return err
+	}
+	defer stream.Close()
+
+	body := io.Reader(stream.Body)
+	switch {
+	case stream.StatusCode == http.StatusPartialContent:
+		if start, total, ok := parseContentRange(stream.Header.Get("Content-Range")); ok {
+			if start != w.written {
+				err := fmt.Errorf("unexpected range start %d, want %d", start, w.written)
+				return errors.NewClientError(errors.InvalidFormatErrorCode, fmt.Sprintf(errors.InvalidFormatErrorMessage, "Content-Range"), err)
+			}
+			w.total = total
+		}
+	case w.written > 0:
+		// 服务端不支持 Range, 跳过已下载的部分
+		if _, err := io.CopyN(io.Discard, body, w.written); err != nil {
+			errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
+			return errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
+		}
+		w.total = stream.ContentLength
+	default:
+		w.total = stream.ContentLength
+	}
+
+	if maxBytes > 0 {
+		if w.total > maxBytes {
+			return errors.NewClientError(errors.BodyTooLargeErrorCode, fmt.Sprintf(errors.BodyTooLargeErrorMessage, maxBytes), nil)
+		}
+		// 多读取1个字节用于判断是否超出限制
+		body = io.LimitReader(body, maxBytes-w.written+1)
+	}
+
+	_, err = io.Copy(w, body)
+	if err != nil {
+		if w.err != nil {
+			return w.err
+		}
+		errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
+		return errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
+	}
+
+	if maxBytes > 0 && w.written > maxBytes {
+		return errors.NewClientError(errors.BodyTooLargeErrorCode, fmt.Sprintf(errors.BodyTooLargeErrorMessage, maxBytes), nil)
+	}
+
+	// 连接提前关闭导致内容不完整
+	if w.total > 0 && w.written < w.total {
+		err := io.ErrUnexpectedEOF
+		errMsg := fmt.Sprintf(errors.NetWorkErrorMessage, err.Error())
+		return errors.NewClientError(errors.NetWorkErrorCode, errMsg, err)
+	}
+
+	return nil
+}
+
+// 统计写入字节数并回调进度
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress func(written, total int64)
+	err      error // 写入目标的错误
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if err != nil {
+		p.err = err
+		return n, err
+	}
+	if p.progress != nil {
+		p.progress(p.written, p.total)
+	}
+	return n, nil
+}
+
+// 解析 Content-Range: bytes start-end/total, total 未知时为 -1
+func parseContentRange(value string) (start, total int64, ok bool) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "bytes ") {
+		return 0, 0, false
+	}
+
+	rng, size, found := strings.Cut(strings.TrimPrefix(value, "bytes "), "/")
+	if !found {
+		return 0, 0, false
+	}
+
+	first, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+
+	return start, total, true
+}
@@ -0,0 +1,187 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	commonErrors "github.com/shzy2012/common/errors"
+)
+
+// 测试流式读取响应
+func TestClient_Stream(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "stream")
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "line%d\n", i)
+		}
+	})
+	defer server.Close()
+
+	client := NewClient()
+	stream, err := client.Stream(context.Background(), "GET", server.URL, nil)
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	defer stream.Close()
+
+	if stream.StatusCode != http.StatusOK || stream.Header.Get("X-Test") != "stream" {
+		t.Errorf("Unexpected stream response: %d %v", stream.StatusCode, stream.Header)
+	}
+
+	body, _ := io.ReadAll(stream.Body)
+	if string(body) != "line0\nline1\nline2\n" {
+		t.Errorf("Unexpected body '%s'", body)
+	}
+}
+
+// 测试流式请求的错误状态码
+func TestClient_Stream_ErrorStatus(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "denied", http.StatusForbidden)
+	})
+	defer server.Close()
+
+	client := NewClient()
+	_, err := client.Stream(context.Background(), "GET", server.URL, nil)
+	e, ok := err.(commonErrors.Error)
+	if !ok || e.HttpStatus() != http.StatusForbidden {
+		t.Fatalf("Expected 403 ServerError, got %v", err)
+	}
+	if !strings.Contains(e.Message(), "denied") {
+		t.Errorf("Expected message to contain 'denied', got '%s'", e.Message())
+	}
+}
+
+// 测试下载进度和大小限制
+func TestClient_Download(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(content))
+	})
+	defer server.Close()
+
+	client := NewClient()
+
+	var dst bytes.Buffer
+	var last, total int64
+	n, err := client.Download(context.Background(), server.URL, &dst, &DownloadOptions{
+		Progress: func(written, size int64) {
+			last, total = written, size
+		},
+	})
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+
+	if n != int64(len(content)) || !bytes.Equal(dst.Bytes(), content) {
+		t.Errorf("Downloaded %d bytes, content mismatch", n)
+	}
+
+	if last != int64(len(content)) || total != int64(len(content)) {
+		t.Errorf("Unexpected progress %d/%d", last, total)
+	}
+
+	_, err = client.Download(context.Background(), server.URL, io.Discard, &DownloadOptions{MaxBytes: 100})
+	e, ok := err.(commonErrors.Error)
+	if !ok || e.ErrorCode() != commonErrors.BodyTooLargeErrorCode {
+		t.Errorf("Expected BodyTooLarge error, got %v", err)
+	}
+}
+
+// 测试传输中断后通过 Range 续传
+func TestClient_Download_Resume(t *testing.T) {
+	content := bytes.Repeat([]byte("abcdefghij"), 1000)
+	var requests int32
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			// 第一次只返回一半内容后断开连接
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+			w.WriteHeader(http.StatusOK)
+			w.Write(content[:len(content)/2])
+			w.(http.Flusher).Flush()
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		if r.Header.Get("Range") != fmt.Sprintf("bytes=%d-", len(content)/2) {
+			t.Errorf("Unexpected Range header '%s'", r.Header.Get("Range"))
+		}
+		http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(content))
+	})
+	defer server.Close()
+
+	client := NewClient()
+	var dst bytes.Buffer
+	n, err := client.Download(context.Background(), server.URL, &dst, &DownloadOptions{MaxResumes: 1})
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+
+	if n != int64(len(content)) || !bytes.Equal(dst.Bytes(), content) {
+		t.Errorf("Downloaded %d bytes, content mismatch", n)
+	}
+
+	if atomic.LoadInt32(&requests) != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		value        string
+		start, total int64
+		ok           bool
+	}{
+		{"bytes 100-199/1000", 100, 1000, true},
+		{"bytes 0-99/*", 0, -1, true},
+		{"bytes */1000", 0, 0, false},
+		{"items 0-1/2", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		start, total, ok := parseContentRange(tt.value)
+		if start != tt.start || total != tt.total || ok != tt.ok {
+			t.Errorf("parseContentRange(%q) = %d, %d, %v", tt.value, start, total, ok)
+		}
+	}
+}
+
+// 测试流式请求的超时只作用于等待响应头的阶段
+func TestClient_Stream_Timeout(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow-header" {
+			time.Sleep(200 * time.Millisecond)
+			return
+		}
+		w.Write([]byte("a"))
+		w.(http.Flusher).Flush()
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("b"))
+	})
+	defer server.Close()
+
+	client := NewClient()
+	client.SetHTTPTimeout(100 * time.Millisecond)
+
+	stream, err := client.Stream(context.Background(), "GET", server.URL, nil)
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	body, err := io.ReadAll(stream.Body)
+	stream.Close()
+	if err != nil || string(body) != "ab" {
+		t.Errorf("Expected body 'ab', got '%s' (%v)", body, err)
+	}
+
+	_, err = client.Stream(context.Background(), "GET", server.URL+"/slow-header", nil)
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package network
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// 默认的 TLS 配置: 校验服务端证书, 最低 TLS 1.2
+func defaultTLSConfig() *tls.Config {
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
+
+// 使用自定义的 TLS 配置
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) error {
+		if config == nil {
+			config = defaultTLSConfig()
+		}
+		c.transport.TLSClientConfig = config.Clone()
+		return nil
+	}
+}
+
+// 跳过服务端证书校验, 仅用于测试或内网环境
+func WithInsecureSkipVerify() Option {
+	return func(c *Client) error {
+		c.transport.TLSClientConfig.InsecureSkipVerify = true
+		return nil
+	}
+}
+
+// 设置最低的 TLS 版本, 例如 tls.VersionTLS13
+func WithMinTLSVersion(version uint16) Option {
+	return func(c *Client) error {
+		c.transport.TLSClientConfig.MinVersion = version
+		return nil
+	}
+}
+
+// 从文件加载 CA 证书, 追加到系统根证书之后
+func WithCACertFile(path string) Option {
+	return func(c *Client) error {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read ca file: %w", err)
+		}
+		return addRootCAs(c, pem)
+	}
+}
+
+// 加载 PEM 格式的 CA 证书, 追加到系统根证书之后
+func WithCACertPEM(pem []byte) Option {
+	return func(c *Client) error {
+		return addRootCAs(c, pem)
+	}
+}
+
+func addRootCAs(c *Client, pem []byte) error {
+	config := c.transport.TLSClientConfig
+	if config.RootCAs == nil {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		config.RootCAs = pool
+	}
+
+	if !config.RootCAs.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no valid ca certificate found in pem")
+	}
+	return nil
+}
+
+// 从文件加载双向认证(mTLS)的客户端证书
+func WithClientCertFile(certFile, keyFile string) Option {
+	return func(c *Client) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("load client certificate: %w", err)
+		}
+		c.transport.TLSClientConfig.Certificates = append(c.transport.TLSClientConfig.Certificates, cert)
+		return nil
+	}
+}
+
+// 加载 PEM 格式的双向认证(mTLS)客户端证书
+func WithClientCertPEM(certPEM, keyPEM []byte) Option {
+	return func(c *Client) error {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return fmt.Errorf("load client certificate: %w", err)
+		}
+		c.transport.TLSClientConfig.Certificates = append(c.transport.TLSClientConfig.Certificates, cert)
+		return nil
+	}
+}
+
+/* 固定服务端证书指纹
+* fingerprints: 证书 DER 编码的 SHA-256 指纹(十六进制, 可包含冒号)
+* 证书链中任意一个证书匹配即通过, 在常规证书校验之外额外校验
+ */
+func WithPinnedCertificates(fingerprints ...string) Option {
+	return func(c *Client) error {
+		pins := make(map[string]bool, len(fingerprints))
+		for _, fp := range fingerprints {
+			fp = strings.ToLower(strings.ReplaceAll(fp, ":", ""))
+			if b, err := hex.DecodeString(fp); err != nil || len(b) != sha256.Size {
+				return fmt.Errorf("invalid sha256 fingerprint: %s", fp)
+			}
+			pins[fp] = true
+		}
+
+		c.transport.TLSClientConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			for _, cert := range cs.PeerCertificates {
+				if pins[CertificateFingerprint(cert)] {
+					return nil
+				}
+			}
+			return fmt.Errorf("certificate of %s does not match any pinned fingerprint", cs.ServerName)
+		}
+		return nil
+	}
+}
+
+// 计算证书的 SHA-256 指纹(小写十六进制)
+func CertificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
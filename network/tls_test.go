@@ -0,0 +1,159 @@
+package network
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func createTLSServer() *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+}
+
+// 服务端证书的 PEM 编码
+func serverCertPEM(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+// 测试默认校验服务端证书
+func TestNewClient_VerifyByDefault(t *testing.T) {
+	server := createTLSServer()
+	defer server.Close()
+
+	if _, err := NewClient().Request("GET", server.URL, nil, 0); err == nil {
+		t.Error("Expected certificate verification error for self-signed server")
+	}
+
+	response, err := NewClient(WithInsecureSkipVerify()).Request("GET", server.URL, nil, 0)
+	if err != nil || response.ToString() != "ok" {
+		t.Errorf("Expected insecure request to succeed, got %v", err)
+	}
+}
+
+// 测试加载自定义 CA
+func TestNewClient_CACert(t *testing.T) {
+	server := createTLSServer()
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, serverCertPEM(server), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(WithCACertFile(caFile), WithMinTLSVersion(tls.VersionTLS12))
+	if _, err := client.Request("GET", server.URL, nil, 0); err != nil {
+		t.Errorf("Request with custom CA failed: %v", err)
+	}
+
+	client = NewClient(WithCACertFile(filepath.Join(t.TempDir(), "missing.pem")))
+	if client.Err() == nil {
+		t.Fatal("Expected error for missing CA file")
+	}
+	if _, err := client.Request("GET", server.URL, nil, 0); err == nil {
+		t.Error("Expected request to fail when options are invalid")
+	}
+}
+
+// 测试证书指纹固定
+func TestNewClient_PinnedCertificates(t *testing.T) {
+	server := createTLSServer()
+	defer server.Close()
+
+	fingerprint := CertificateFingerprint(server.Certificate())
+
+	client := NewClient(WithCACertPEM(serverCertPEM(server)), WithPinnedCertificates(fingerprint))
+	if _, err := client.Request("GET", server.URL, nil, 0); err != nil {
+		t.Errorf("Request with matching pin failed: %v", err)
+	}
+
+	other := "00:11:22:33:44:55:66:77:88:99:aa:bb:cc:dd:ee:ff:00:11:22:33:44:55:66:77:88:99:aa:bb:cc:dd:ee:ff"
+	client = NewClient(WithCACertPEM(serverCertPEM(server)), WithPinnedCertificates(other))
+	if _, err := client.Request("GET", server.URL, nil, 0); err == nil {
+		t.Error("Expected error for mismatched pin")
+	}
+
+	if NewClient(WithPinnedCertificates("abc")).Err() == nil {
+		t.Error("Expected error for invalid fingerprint")
+	}
+}
+
+// 测试双向认证
+func TestNewClient_ClientCert(t *testing.T) {
+	certPEM, keyPEM, cert := generateClientCert(t)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	server.StartTLS()
+	defer server.Close()
+
+	if _, err := NewClient(WithCACertPEM(serverCertPEM(server))).Request("GET", server.URL, nil, 0); err == nil {
+		t.Error("Expected error without client certificate")
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	os.WriteFile(certFile, certPEM, 0600)
+	os.WriteFile(keyFile, keyPEM, 0600)
+
+	client := NewClient(WithCACertPEM(serverCertPEM(server)), WithClientCertFile(certFile, keyFile))
+	response, err := client.Request("GET", server.URL, nil, 0)
+	if err != nil {
+		t.Fatalf("Request with client certificate failed: %v", err)
+	}
+
+	if response.ToString() != "test-client" {
+		t.Errorf("Expected client CN 'test-client', got '%s'", response.ToString())
+	}
+}
+
+// 生成自签名的客户端证书
+func generateClientCert(t *testing.T) ([]byte, []byte, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, _ := x509.ParseCertificate(der)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, cert
+}
@@ -0,0 +1,367 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultRequestIDHeader 默认的请求 ID 请求头
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// 调试日志中脱敏后的值
+const redacted = "REDACTED"
+
+// 调试日志中默认脱敏的请求头和响应头
+var defaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// 调试日志中默认脱敏的 JSON 字段、表单字段和查询参数
+var defaultRedactFields = []string{"password", "passwd", "secret", "client_secret", "token", "access_token", "refresh_token", "api_key", "apikey", "authorization"}
+
+// TraceConfig 请求 ID、traceparent 传播和调试日志的配置
+type TraceConfig struct {
+	RequestIDHeader string   // 请求 ID 的请求头, 默认 X-Request-ID
+	GenerateID      bool     // Context 中没有请求 ID 时自动生成
+	StartTrace      bool     // Context 中没有 traceparent 时开启新的 trace
+	RedactHeaders   []string // 调试日志中额外脱敏的请求头和响应头
+	RedactFields    []string // 调试日志中额外脱敏的 JSON 字段、表单字段和查询参数
+	MaxBodyLog      int      // 调试日志中请求体和响应体的最大长度, 默认 1024, 小于 0 时不输出
+}
+
+type requestIDKey struct{}
+
+type traceParentKey struct{}
+
+// 返回携带请求 ID 的 Context, 使用该 Context 发起的请求会在请求头中带上该 ID
+/* example
+ctx := network.ContextWithRequestID(ctx, r.Header.Get("X-Request-ID"))
+network.GetWithContext(ctx, url)
+*/
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// 获取 Context 中的请求 ID
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// 返回携带 W3C traceparent 的 Context, 请求时沿用其中的 trace-id 并生成新的 parent-id
+// traceparent 格式不正确时忽略
+func ContextWithTraceParent(ctx context.Context, traceparent string) context.Context {
+	tp, ok := parseTraceParent(traceparent)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, traceParentKey{}, tp)
+}
+
+// 获取 Context 中的 traceparent, 没有时返回空字符串
+func TraceParentFromContext(ctx context.Context) string {
+	tp, ok := ctx.Value(traceParentKey{}).(traceParent)
+	if !ok {
+		return ""
+	}
+	return tp.String()
+}
+
+// W3C traceparent: version-traceid-parentid-flags
+type traceParent struct {
+	traceID string
+	spanID  string
+	flags   string
+}
+
+func (tp traceParent) String() string {
+	return "00-" + tp.traceID + "-" + tp.spanID + "-" + tp.flags
+}
+
+func parseTraceParent(s string) (traceParent, bool) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return traceParent{}, false
+	}
+
+	tp := traceParent{traceID: parts[1], spanID: parts[2], flags: parts[3]}
+	if !isHex(tp.traceID, 32) || !isHex(tp.spanID, 16) || !isHex(tp.flags, 2) ||
+		tp.traceID == strings.Repeat("0", 32) || tp.spanID == strings.Repeat("0", 16) {
+		return traceParent{}, false
+	}
+	return tp, true
+}
+
+// 小写的十六进制字符串
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// UUID v4 格式的请求 ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// 开启请求 ID 和 traceparent 传播
+/* example
+client := network.NewClient(network.WithTracing(network.TraceConfig{GenerateID: true, StartTrace: true}))
+ctx := network.ContextWithRequestID(ctx, "req-1")
+ctx = network.ContextWithTraceParent(ctx, r.Header.Get("traceparent"))
+client.RequestWithContext(ctx, "GET", url, nil, 0)
+*/
+func WithTracing(config TraceConfig) Option {
+	return func(c *Client) error {
+		c.SetTracing(&config)
+		return nil
+	}
+}
+
+// 设置请求 ID、traceparent 传播和调试日志, 为 nil 时恢复默认:
+// 只传播 Context 中已有的请求 ID 和 traceparent, 调试日志按默认规则脱敏
+func (c *Client) SetTracing(config *TraceConfig) {
+	if config == nil {
+		c.tracer = nil
+		return
+	}
+	c.tracer = newTracer(*config)
+}
+
+// 请求 ID、traceparent 和调试日志
+type tracer struct {
+	config        TraceConfig
+	redactHeaders map[string]bool
+	redactFields  map[string]bool
+}
+
+var defaultTracer = newTracer(TraceConfig{})
+
+func newTracer(config TraceConfig) *tracer {
+	if config.RequestIDHeader == "" {
+		config.RequestIDHeader = DefaultRequestIDHeader
+	}
+	if config.MaxBodyLog == 0 {
+		config.MaxBodyLog = 1024
+	}
+
+	t := &tracer{config: config, redactHeaders: map[string]bool{}, redactFields: map[string]bool{}}
+	for _, key := range append(append([]string{}, defaultRedactHeaders...), config.RedactHeaders...) {
+		t.redactHeaders[http.CanonicalHeaderKey(key)] = true
+	}
+	for _, key := range append(append([]string{}, defaultRedactFields...), config.RedactFields...) {
+		t.redactFields[strings.ToLower(key)] = true
+	}
+	return t
+}
+
+func (c *Client) trace() *tracer {
+	if c.tracer == nil {
+		return defaultTracer
+	}
+	return c.tracer
+}
+
+// 设置请求 ID 和 traceparent 请求头, 请求中已有的不覆盖
+func (t *tracer) inject(req *http.Request) {
+	ctx := req.Context()
+
+	if req.Header.Get(t.config.RequestIDHeader) == "" {
+		id := RequestIDFromContext(ctx)
+		if id == "" && t.config.GenerateID {
+			id = newRequestID()
+		}
+		if id != "" {
+			req.Header.Set(t.config.RequestIDHeader, id)
+		}
+	}
+
+	if req.Header.Get("traceparent") == "" {
+		tp, ok := ctx.Value(traceParentKey{}).(traceParent)
+		if !ok && t.config.StartTrace {
+			tp, ok = traceParent{traceID: randomHex(16), flags: "01"}, true
+		}
+		if ok {
+			tp.spanID = randomHex(8)
+			req.Header.Set("traceparent", tp.String())
+		}
+	}
+}
+
+// 调试日志中关联请求和响应的 ID: 优先使用请求 ID, 其次 trace-id
+func (t *tracer) logID(req *http.Request) string {
+	if id := req.Header.Get(t.config.RequestIDHeader); id != "" {
+		return id
+	}
+	if tp, ok := parseTraceParent(req.Header.Get("traceparent")); ok {
+		return tp.traceID
+	}
+	return randomHex(4)
+}
+
+// 请求的调试日志
+func (t *tracer) logRequest(logger Logger, id string, attempt int, req *http.Request, body []byte) {
+	logger.Debugf("[http_request] id=%s attempt=%d method=%s url=%q header=%s body=%s\n",
+		id, attempt, req.Method, t.redactURL(req.URL), t.header(req.Header), t.body(req.Header, body, req.Body != nil && body == nil))
+}
+
+// 响应头的调试日志, 每次发送后输出
+func (t *tracer) logResponse(logger Logger, id string, attempt int, resp *http.Response, err error, duration time.Duration) {
+	if err != nil {
+		logger.Debugf("[http_response] id=%s attempt=%d duration=%s error=%q\n", id, attempt, duration, err.Error())
+		return
+	}
+	logger.Debugf("[http_response] id=%s attempt=%d duration=%s status=%d header=%s\n", id, attempt, duration, resp.StatusCode, t.header(resp.Header))
+}
+
+// 响应体的调试日志
+func (t *tracer) logResponseBody(logger Logger, id string, header http.Header, body []byte, duration time.Duration) {
+	logger.Debugf("[http_response_body] id=%s duration=%s bytes=%d body=%s\n", id, duration, len(body), t.body(header, body, false))
+}
+
+// 脱敏后的地址
+func (t *tracer) redactURL(u *url.URL) string {
+	if u.RawQuery == "" && u.User == nil {
+		return u.String()
+	}
+
+	redactedURL := *u
+	if u.User != nil {
+		redactedURL.User = url.User(u.User.Username())
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		t.redactValues(query)
+		redactedURL.RawQuery = query.Encode()
+	}
+	return redactedURL.String()
+}
+
+// 脱敏并排序后的请求头, 格式为 {Key: value, ...}
+func (t *tracer) header(header http.Header) string {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("{")
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		value := strings.Join(header[key], ",")
+		if t.redactHeaders[http.CanonicalHeaderKey(key)] {
+			value = redacted
+		}
+		fmt.Fprintf(&b, "%s: %s", key, value)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// 脱敏并截断后的请求体或响应体
+func (t *tracer) body(header http.Header, body []byte, stream bool) string {
+	switch {
+	case stream:
+		return "<stream>"
+	case t.config.MaxBodyLog < 0:
+		return fmt.Sprintf("<%d bytes>", len(body))
+	case len(body) == 0:
+		return `""`
+	case !utf8.Valid(body):
+		return fmt.Sprintf("<binary %d bytes>", len(body))
+	}
+
+	contentType := header.Get(ContentType)
+	switch {
+	case strings.Contains(contentType, "json") || json.Valid(body):
+		body = t.redactJSON(body)
+	case strings.HasPrefix(contentType, XwwwFormUrlencoded):
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			t.redactValues(values)
+			body = []byte(values.Encode())
+		}
+	}
+
+	if len(body) > t.config.MaxBodyLog {
+		return fmt.Sprintf("%q...(%d bytes)", body[:t.config.MaxBodyLog], len(body))
+	}
+	return fmt.Sprintf("%q", body)
+}
+
+func (t *tracer) redactValues(values url.Values) {
+	for key := range values {
+		if t.redactFields[strings.ToLower(key)] {
+			values[key] = []string{redacted}
+		}
+	}
+}
+
+// 脱敏 JSON 中的字段, 不是合法的 JSON 时原样返回
+func (t *tracer) redactJSON(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return body
+	}
+	if !t.redactTree(v) {
+		return body
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// 递归脱敏, 返回是否有字段被脱敏
+func (t *tracer) redactTree(v interface{}) bool {
+	changed := false
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for key, value := range x {
+			if t.redactFields[strings.ToLower(key)] {
+				x[key] = redacted
+				changed = true
+			} else if t.redactTree(value) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, value := range x {
+			if t.redactTree(value) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
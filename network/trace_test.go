@@ -0,0 +1,123 @@
+package network
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// 测试请求 ID 的传播和生成
+func TestClient_RequestID(t *testing.T) {
+	var calls int32
+	var ids []string
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get("X-Correlation-ID"))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	defer server.Close()
+
+	client := NewClient(WithTracing(TraceConfig{RequestIDHeader: "X-Correlation-ID", GenerateID: true}))
+
+	// 重试时使用相同的请求 ID
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+	if _, err := client.RequestWithContext(ctx, "GET", server.URL, nil, 1); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "req-1" || ids[1] != "req-1" {
+		t.Errorf("Expected request id from context on every attempt, got %v", ids)
+	}
+
+	client.Request("GET", server.URL, nil, 0)
+	if id := ids[2]; len(id) != 36 || id[14] != '4' {
+		t.Errorf("Expected generated uuid, got '%s'", id)
+	}
+
+	// 未开启时只传播 Context 中的 ID
+	ids = nil
+	NewClient().RequestWithContext(ctx, "GET", server.URL, nil, 0)
+	NewClient().Request("GET", server.URL, nil, 0)
+	if len(ids) != 2 || ids[0] != "" || ids[1] != "" {
+		t.Errorf("Expected custom header to be unused by default client, got %v", ids)
+	}
+}
+
+// 测试 W3C traceparent 传播
+func TestClient_TraceParent(t *testing.T) {
+	var got string
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("traceparent")
+	})
+	defer server.Close()
+
+	parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := ContextWithTraceParent(context.Background(), parent)
+	if TraceParentFromContext(ctx) != parent {
+		t.Fatalf("Unexpected traceparent in context '%s'", TraceParentFromContext(ctx))
+	}
+
+	NewClient().RequestWithContext(ctx, "GET", server.URL, nil, 0)
+	tp, ok := parseTraceParent(got)
+	if !ok || tp.traceID != "4bf92f3577b34da6a3ce929d0e0e4736" || tp.spanID == "00f067aa0ba902b7" || tp.flags != "01" {
+		t.Errorf("Expected same trace with new span, got '%s'", got)
+	}
+
+	got = ""
+	NewClient().Request("GET", server.URL, nil, 0)
+	if got != "" {
+		t.Errorf("Expected no traceparent by default, got '%s'", got)
+	}
+
+	NewClient(WithTracing(TraceConfig{StartTrace: true})).Request("GET", server.URL, nil, 0)
+	if _, ok := parseTraceParent(got); !ok {
+		t.Errorf("Expected new trace, got '%s'", got)
+	}
+
+	for _, invalid := range []string{"", "00-xyz", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"} {
+		if TraceParentFromContext(ContextWithTraceParent(context.Background(), invalid)) != "" {
+			t.Errorf("Expected invalid traceparent '%s' to be ignored", invalid)
+		}
+	}
+}
+
+// 测试调试日志的关联和脱敏
+func TestClient_DebugLogRedaction(t *testing.T) {
+	server := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=cookie-secret")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"resp-secret","user":"joey"}`))
+	})
+	defer server.Close()
+
+	logger := &testLogger{}
+	client := NewClient(WithDebug(true), WithLogger(logger), WithBasicAuth("user", "basic-secret"),
+		WithHeader("X-Sign", "sign-secret"), WithTracing(TraceConfig{RedactHeaders: []string{"X-Sign"}}))
+
+	ctx := ContextWithRequestID(context.Background(), "req-42")
+	body := []byte(`{"name":"joey","password":"body-secret","nested":[{"token":"nested-secret"}]}`)
+	if _, err := client.RequestWithContext(ctx, "POST", server.URL+"/login?token=query-secret&page=1", body, 0); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+
+	output := strings.Join(logger.lines, "")
+	for _, secret := range []string{"basic-secret", "dXNlcjpiYXNpYy1zZWNyZXQ", "sign-secret", "body-secret", "nested-secret", "query-secret", "resp-secret", "cookie-secret"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Expected %q to be redacted in logs:\n%s", secret, output)
+		}
+	}
+
+	if len(logger.lines) != 3 {
+		t.Fatalf("Expected request, response and body lines, got %d:\n%s", len(logger.lines), output)
+	}
+	for _, line := range logger.lines {
+		if !strings.Contains(line, "id=req-42") {
+			t.Errorf("Expected correlation id in '%s'", line)
+		}
+	}
+	if !strings.Contains(logger.lines[1], "status=200") || !strings.Contains(output, "page=1") || !strings.Contains(output, "joey") {
+		t.Errorf("Expected status and non-sensitive fields in logs:\n%s", output)
+	}
+}
@@ -0,0 +1,369 @@
+package network
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shzy2012/common/errors"
+	"github.com/shzy2012/common/tools"
+)
+
+// BalanceStrategy 负载均衡策略
+type BalanceStrategy int
+
+const (
+	WeightedRoundRobin BalanceStrategy = iota // 加权轮询(tools.RRW)
+	LeastConnections                          // 最少连接, 按权重折算
+	WeightedRandom                            // 加权随机
+)
+
+func (s BalanceStrategy) String() string {
+	switch s {
+	case WeightedRoundRobin:
+		return "weighted-round-robin"
+	case LeastConnections:
+		return "least-connections"
+	case WeightedRandom:
+		return "weighted-random"
+	}
+	return "unknown"
+}
+
+// Endpoint 上游服务节点
+type Endpoint struct {
+	URL    string // 节点的基础地址, 例如 http://10.0.0.1:8080/api
+	Weight int    // 权重, 默认 1
+}
+
+// UpstreamConfig 上游服务组配置
+type UpstreamConfig struct {
+	Endpoints     []Endpoint
+	Strategy      BalanceStrategy                           // 负载均衡策略, 默认加权轮询
+	MaxFails      int                                       // 连续失败达到该次数后摘除节点, 默认 1
+	EjectDuration time.Duration                             // 节点被摘除的时间, 默认 30s
+	IsFailure     func(resp *http.Response, err error) bool // 判断请求是否失败, 默认网络错误或 5xx
+}
+
+// UpstreamEndpointStat 上游节点状态
+type UpstreamEndpointStat struct {
+	URL          string
+	Weight       int
+	Active       int       // 进行中的请求数
+	Ejected      bool      // 是否处于摘除状态
+	EjectedUntil time.Time // 摘除结束的时间
+}
+
+// 上游服务组, 一个逻辑服务名对应多个节点
+/* example
+client := network.NewClient(network.WithUpstream("user-svc", network.UpstreamConfig{
+	Endpoints: []network.Endpoint{
+		{URL: "http://10.0.0.1:8080", Weight: 5},
+		{URL: "http://10.0.0.2:8080", Weight: 2},
+	},
+}))
+resp, err := client.Request("GET", "http://user-svc/users/42", nil, 0)
+*/
+type upstreamGroup struct {
+	config    UpstreamConfig
+	mu        sync.Mutex
+	endpoints []*upstreamEndpoint
+	rrw       *tools.RRW
+	total     int // 权重之和
+}
+
+// 上游节点
+type upstreamEndpoint struct {
+	url          *url.URL
+	weight       int
+	active       int
+	fails        int
+	ejectedUntil time.Time
+}
+
+func newUpstreamGroup(config UpstreamConfig) (*upstreamGroup, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, fmt.Errorf("upstream has no endpoints")
+	}
+	if config.MaxFails <= 0 {
+		config.MaxFails = 1
+	}
+	if config.EjectDuration <= 0 {
+		config.EjectDuration = 30 * time.Second
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isCircuitFailure
+	}
+
+	g := &upstreamGroup{config: config, rrw: &tools.RRW{}}
+	for _, endpoint := range config.Endpoints {
+		u, err := url.Parse(endpoint.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint url: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("invalid endpoint url: %s", endpoint.URL)
+		}
+
+		weight := endpoint.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+
+		ep := &upstreamEndpoint{url: u, weight: weight}
+		g.endpoints = append(g.endpoints, ep)
+		g.rrw.Add(ep, weight)
+		g.total += weight
+	}
+	return g, nil
+}
+
+// 选择节点, 优先选择未摘除的节点, 全部摘除时忽略摘除状态
+func (g *upstreamGroup) pick(tried map[*upstreamEndpoint]bool) *upstreamEndpoint {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	ep := g.next(func(ep *upstreamEndpoint) bool {
+		return !tried[ep] && !now.Before(ep.ejectedUntil)
+	})
+	if ep == nil {
+		ep = g.next(func(ep *upstreamEndpoint) bool {
+			return !tried[ep]
+		})
+	}
+	return ep
+}
+
+// 按策略选择满足条件的节点
+func (g *upstreamGroup) next(accept func(ep *upstreamEndpoint) bool) *upstreamEndpoint {
+	switch g.config.Strategy {
+	case LeastConnections:
+		var best *upstreamEndpoint
+		for _, ep := range g.endpoints {
+			if !accept(ep) {
+				continue
+			}
+			// active/weight 最小的节点
+			if best == nil || ep.active*best.weight < best.active*ep.weight {
+				best = ep
+			}
+		}
+		return best
+	case WeightedRandom:
+		var candidates []*upstreamEndpoint
+		total := 0
+		for _, ep := range g.endpoints {
+			if accept(ep) {
+				candidates = append(candidates, ep)
+				total += ep.weight
+			}
+		}
+		if total == 0 {
+			return nil
+		}
+		n := rand.Intn(total)
+		for _, ep := range candidates {
+			if n < ep.weight {
+				return ep
+			}
+			n -= ep.weight
+		}
+		return nil
+	default:
+		// 一个轮询周期内依次跳过不满足条件的节点
+		for i := 0; i < g.total; i++ {
+			ep, _ := g.rrw.Next().(*upstreamEndpoint)
+			if ep != nil && accept(ep) {
+				return ep
+			}
+		}
+		return nil
+	}
+}
+
+// 记录请求结果, 连续失败达到阈值后摘除节点
+func (g *upstreamGroup) report(ep *upstreamEndpoint, failed bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !failed {
+		ep.fails = 0
+		return
+	}
+
+	ep.fails++
+	if ep.fails >= g.config.MaxFails {
+		ep.fails = 0
+		ep.ejectedUntil = time.Now().Add(g.config.EjectDuration)
+	}
+}
+
+func (g *upstreamGroup) acquire(ep *upstreamEndpoint) {
+	g.mu.Lock()
+	ep.active++
+	g.mu.Unlock()
+}
+
+func (g *upstreamGroup) release(ep *upstreamEndpoint) {
+	g.mu.Lock()
+	ep.active--
+	g.mu.Unlock()
+}
+
+// 将逻辑服务地址改写为节点地址
+func (g *upstreamGroup) rewrite(req *http.Request, ep *upstreamEndpoint) *http.Request {
+	epReq := req.Clone(req.Context())
+	target := *ep.url
+	target.Path = strings.TrimRight(ep.url.Path, "/") + req.URL.Path
+	target.RawPath = strings.TrimRight(ep.url.EscapedPath(), "/") + req.URL.EscapedPath()
+	target.RawQuery = req.URL.RawQuery
+	epReq.URL = &target
+	epReq.Host = ""
+	return epReq
+}
+
+// 发送请求, 节点失败时摘除并在下一个节点上重试
+func (g *upstreamGroup) send(c *Client, httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	var lastResp *http.Response
+	var lastErr error
+
+	tried := make(map[*upstreamEndpoint]bool, len(g.endpoints))
+	for len(tried) < len(g.endpoints) {
+		if len(tried) > 0 {
+			// 请求已取消或请求体无法重放时不再切换节点
+			if req.Context().Err() != nil {
+				break
+			}
+			if req.Body != nil && req.Body != http.NoBody {
+				if req.GetBody == nil {
+					break
+				}
+				body, err := req.GetBody()
+				if err != nil {
+					break
+				}
+				req.Body = body
+			}
+		}
+
+		ep := g.pick(tried)
+		if ep == nil {
+			break
+		}
+		tried[ep] = true
+
+		g.acquire(ep)
+		resp, err := c.sendOnce(httpClient, g.rewrite(req, ep))
+		if err != nil {
+			g.release(ep)
+			// 节点熔断时切换到下一个节点
+			if isCircuitOpen(err) {
+				if lastResp == nil {
+					lastErr = err
+				}
+				continue
+			}
+		} else {
+			// 关闭响应体时释放节点的连接计数
+			resp.Body = &notifyBody{ReadCloser: resp.Body, closed: func() { g.release(ep) }}
+		}
+
+		failed := g.config.IsFailure(resp, err)
+		g.report(ep, failed)
+
+		if lastResp != nil {
+			lastResp.Body.Close()
+		}
+		lastResp, lastErr = resp, err
+		if !failed {
+			break
+		}
+	}
+
+	return lastResp, lastErr
+}
+
+func (g *upstreamGroup) stats() []UpstreamEndpointStat {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	stats := make([]UpstreamEndpointStat, 0, len(g.endpoints))
+	for _, ep := range g.endpoints {
+		stats = append(stats, UpstreamEndpointStat{
+			URL:          ep.url.String(),
+			Weight:       ep.weight,
+			Active:       ep.active,
+			Ejected:      now.Before(ep.ejectedUntil),
+			EjectedUntil: ep.ejectedUntil,
+		})
+	}
+	return stats
+}
+
+// 设置上游服务组, 请求地址的 host 为 name 时按策略转发到组内节点
+func (c *Client) SetUpstream(name string, config UpstreamConfig) error {
+	group, err := newUpstreamGroup(config)
+	if err != nil {
+		return errors.NewClientError(errors.InvalidParamErrorCode, err.Error(), err)
+	}
+	c.setUpstream(name, group)
+	return nil
+}
+
+// 删除上游服务组
+func (c *Client) RemoveUpstream(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.upstreams, strings.ToLower(name))
+}
+
+// 设置上游服务组
+func WithUpstream(name string, config UpstreamConfig) Option {
+	return func(c *Client) error {
+		group, err := newUpstreamGroup(config)
+		if err != nil {
+			return err
+		}
+		c.setUpstream(name, group)
+		return nil
+	}
+}
+
+// 获取各上游服务组的节点状态
+func (c *Client) UpstreamStats() map[string][]UpstreamEndpointStat {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stats := make(map[string][]UpstreamEndpointStat, len(c.upstreams))
+	for name, group := range c.upstreams {
+		stats[name] = group.stats()
+	}
+	return stats
+}
+
+func (c *Client) setUpstream(name string, group *upstreamGroup) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.upstreams == nil {
+		c.upstreams = map[string]*upstreamGroup{}
+	}
+	c.upstreams[strings.ToLower(name)] = group
+}
+
+// 根据 host 查找上游服务组
+func (c *Client) upstream(host string) *upstreamGroup {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(c.upstreams) == 0 {
+		return nil
+	}
+	return c.upstreams[strings.ToLower(host)]
+}
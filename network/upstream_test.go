@@ -0,0 +1,151 @@
+package network
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	commonErrors "github.com/shzy2012/common/errors"
+)
+
+// 测试按权重轮询分发并改写请求地址
+func TestUpstream_WeightedRoundRobin(t *testing.T) {
+	var hitsA, hitsB int32
+	serverA := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hitsA, 1)
+		w.Write([]byte(r.URL.RequestURI()))
+	})
+	defer serverA.Close()
+	serverB := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hitsB, 1)
+		w.Write([]byte(r.URL.RequestURI()))
+	})
+	defer serverB.Close()
+
+	client := NewClient(WithUpstream("user-svc", UpstreamConfig{
+		Endpoints: []Endpoint{{URL: serverA.URL + "/api/", Weight: 3}, {URL: serverB.URL + "/api", Weight: 1}},
+	}))
+	if client.Err() != nil {
+		t.Fatalf("Unexpected option error: %v", client.Err())
+	}
+
+	for i := 0; i < 8; i++ {
+		response, err := client.Request("GET", "http://user-svc/users/42?x=1", nil, 0)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		if response.ToString() != "/api/users/42?x=1" {
+			t.Errorf("Unexpected rewritten uri '%s'", response.ToString())
+		}
+	}
+
+	if hitsA != 6 || hitsB != 2 {
+		t.Errorf("Expected 6/2 distribution, got %d/%d", hitsA, hitsB)
+	}
+}
+
+// 测试失败节点被摘除并切换到下一个节点
+func TestUpstream_Ejection(t *testing.T) {
+	var bad int32
+	badServer := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&bad, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer badServer.Close()
+	goodServer := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, 16)
+		n, _ := r.Body.Read(buf)
+		w.Write(buf[:n])
+	})
+	defer goodServer.Close()
+
+	client := NewClient()
+	err := client.SetUpstream("svc", UpstreamConfig{
+		Endpoints:     []Endpoint{{URL: badServer.URL}, {URL: goodServer.URL}},
+		EjectDuration: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("SetUpstream failed: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		response, err := client.Request("POST", "svc/echo", []byte("payload"), 0)
+		if err != nil || response.ToString() != "payload" {
+			t.Fatalf("Expected failover to succeed, got '%s' (%v)", response.ToString(), err)
+		}
+	}
+
+	if atomic.LoadInt32(&bad) != 1 {
+		t.Errorf("Expected ejected endpoint to be skipped, got %d calls", bad)
+	}
+
+	stats := client.UpstreamStats()["svc"]
+	if len(stats) != 2 || !stats[0].Ejected || stats[1].Ejected {
+		t.Errorf("Unexpected upstream stats %+v", stats)
+	}
+
+	// 所有节点都失败时返回最后一个响应
+	client.SetUpstream("svc", UpstreamConfig{Endpoints: []Endpoint{{URL: badServer.URL}}})
+	response, err := client.Request("GET", "svc/", nil, 0)
+	if e, ok := err.(commonErrors.Error); !ok || e.HttpStatus() != http.StatusBadGateway || response.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected 502 error, got %v", err)
+	}
+}
+
+// 测试最少连接和随机策略
+func TestUpstream_Strategies(t *testing.T) {
+	release := make(chan struct{})
+	slow := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte("slow"))
+	})
+	defer slow.Close()
+	fast := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fast"))
+	})
+	defer fast.Close()
+
+	client := NewClient(WithUpstream("svc", UpstreamConfig{
+		Strategy:  LeastConnections,
+		Endpoints: []Endpoint{{URL: slow.URL}, {URL: fast.URL}},
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		client.Request("GET", "http://svc/", nil, 0)
+		close(done)
+	}()
+
+	// 等待第一个请求占用 slow 节点
+	for client.UpstreamStats()["svc"][0].Active == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	for i := 0; i < 3; i++ {
+		response, _ := client.Request("GET", "http://svc/", nil, 0)
+		if response.ToString() != "fast" {
+			t.Errorf("Expected least loaded endpoint, got '%s'", response.ToString())
+		}
+	}
+	close(release)
+	<-done
+
+	if active := client.UpstreamStats()["svc"][0].Active; active != 0 {
+		t.Errorf("Expected active count to be released, got %d", active)
+	}
+
+	client.SetUpstream("svc", UpstreamConfig{Strategy: WeightedRandom, Endpoints: []Endpoint{{URL: fast.URL, Weight: 2}, {URL: slow.URL, Weight: 1}}})
+	for i := 0; i < 10; i++ {
+		if _, err := client.Request("GET", "http://svc/", nil, 0); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+	}
+
+	if err := client.SetUpstream("svc", UpstreamConfig{}); err == nil {
+		t.Error("Expected error for empty endpoints")
+	}
+	if err := client.SetUpstream("svc", UpstreamConfig{Endpoints: []Endpoint{{URL: "ftp://x"}}}); err == nil {
+		t.Error("Expected error for invalid endpoint url")
+	}
+}